@@ -27,12 +27,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/master-g/i18n/internal/appender"
+	"github.com/master-g/i18n/internal/baseline"
+	"github.com/master-g/i18n/internal/escape"
+	"github.com/master-g/i18n/internal/journal"
+	"github.com/master-g/i18n/internal/lintformat"
 	"github.com/master-g/i18n/internal/model"
 	"github.com/master-g/i18n/internal/parser"
+	"github.com/master-g/i18n/internal/report"
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/router"
+	"github.com/master-g/i18n/pkg/locale"
 	"github.com/master-g/i18n/pkg/wkfs"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -54,14 +63,79 @@ var appendCmd = &cobra.Command{
 		bindFlag(cmd, flagsKey)
 		bindFlag(cmd, flagsAlias)
 		bindFlag(cmd, flagsDry)
+		bindFlag(cmd, flagsArrayDelimiter)
+		bindFlag(cmd, flagsCreateMissing)
+		bindFlag(cmd, flagsQualifiers)
+		bindFlag(cmd, flagsBaseLanguage)
+		bindFlag(cmd, flagsTargetFile)
+		bindFlag(cmd, flagsManaged)
+		bindFlag(cmd, flagsPrune)
+		bindFlag(cmd, flagsTrack)
+		bindFlag(cmd, flagsTrackPrefix)
+		bindFlag(cmd, flagsStateFile)
+		bindFlag(cmd, flagsObsoleteFile)
+		bindFlag(cmd, flagsOrder)
+		bindFlag(cmd, flagsRoutes)
+		bindFlag(cmd, flagsSourceSet)
+		bindFlag(cmd, flagsJournal)
+		bindFlag(cmd, flagsPatch)
+		bindFlag(cmd, flagsReport)
+		bindFlag(cmd, flagsCheck)
+		bindFlag(cmd, flagsLintRules)
+		bindFlag(cmd, flagsLintBaseline)
+		bindFlag(cmd, flagsUpdateBaseline)
+		bindFlag(cmd, flagsLintFormat)
+		bindFlag(cmd, flagsLintOutput)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		srcFiles := make(map[string]string)
-
-		var absPath string
 		var err error
+		startReport()
+		check := viper.GetBool(flagsCheck)
+		if check && viper.GetBool(flagsInteract) {
+			logrus.Errorf("--%v cannot be used with --%v", flagsCheck, flagsInteract)
+			exit(1)
+		}
+
+		lintRules, err := loadLintRules()
+		if err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+		if viper.GetBool(flagsLintRules) {
+			printLintRules(lintRules)
+			return
+		}
+		lintBaseline, err := baseline.Load(viper.GetString(flagsLintBaseline))
+		if err != nil {
+			logrus.Errorf("cannot load lint baseline, err:%v", err)
+			exit(1)
+		}
+		lint := &lintFilter{rules: lintRules, baseline: lintBaseline}
+		updateBaseline := viper.GetBool(flagsUpdateBaseline)
+		if updateBaseline && viper.GetBool(flagsNoLint) {
+			logrus.Errorf("--%v cannot be used with --%v", flagsUpdateBaseline, flagsNoLint)
+			exit(1)
+		}
+		if format := viper.GetString(flagsLintFormat); format != "" {
+			if !lintformat.Valid(format) {
+				logrus.Errorf("unknown --%v %v, use %v", flagsLintFormat, format, strings.Join(lintformat.Formats, ", "))
+				exit(1)
+			}
+			if viper.GetBool(flagsNoLint) {
+				logrus.Errorf("--%v cannot be used with --%v", flagsLintFormat, flagsNoLint)
+				exit(1)
+			}
+			if viper.GetString(flagsLintOutput) == report.Stdout {
+				if viper.GetString(flagsReport) == report.Stdout || viper.GetBool(flagsInteract) {
+					logrus.Errorf("--%v to stdout cannot be used with --%v %v or --%v", flagsLintFormat, flagsReport, report.Stdout, flagsInteract)
+					exit(1)
+				}
+				logrus.SetOutput(os.Stderr)
+			}
+		}
 
 		// STEP 1. iterate all source parameters, find all .csv files
+		runReport.Begin("sources")
 		logrus.Info("checking sources...")
 		sources := viper.GetStringSlice("src")
 		if len(sources) == 0 {
@@ -69,30 +143,7 @@ var appendCmd = &cobra.Command{
 			//exit(0)
 		}
 
-		var files []string
-		for _, s := range sources {
-			if wkfs.IsFile(s) && mightBeCSVFile(s) {
-				files = append(files, s)
-			} else if wkfs.IsDir(s) {
-				var csvs []string
-				csvs, _, err = wkfs.Scan(s, wkfs.WithFilesOnly(), wkfs.WithTypes("csv"))
-				if err != nil {
-					logrus.Errorf("cannot walk through directory %v, err:%v", s, err)
-					continue
-				}
-				files = append(files, csvs...)
-			}
-		}
-
-		for _, f := range files {
-			absPath, err = filepath.Abs(f)
-			if err != nil {
-				logrus.Errorf("cannot get fullpath for %v, err:%v", f, err)
-				continue
-			}
-			sum := md5.Sum([]byte(absPath))
-			srcFiles[hex.EncodeToString(sum[:])] = absPath
-		}
+		srcFiles := findSourceFiles(sources)
 		logrus.Infof("%d source file(s) found", len(srcFiles))
 		for _, f := range srcFiles {
 			logrus.Info(f)
@@ -101,116 +152,49 @@ var appendCmd = &cobra.Command{
 		// STEP 2. check output directory
 		logrus.Info("checking output directory...")
 		outputDir := viper.GetString("out")
-		if !wkfs.IsDir(outputDir) {
-			logrus.Errorf("'%v' is not a valid output directory", outputDir)
+		targetFile := viper.GetString(flagsTargetFile)
+		if targetFile == "" || filepath.Base(targetFile) != targetFile {
+			logrus.Errorf("'%v' is not a valid target file name", targetFile)
 			exit(1)
 		}
-
-		var folders []string
-		_, folders, err = wkfs.Scan(outputDir, wkfs.WithFoldersOnly())
-		if err != nil {
-			logrus.Errorf("cannot walk through directory %v, err:%v", outputDir, err)
+		if !wkfs.IsDir(outputDir) {
+			logrus.Errorf("'%v' is not a valid output directory", outputDir)
 			exit(1)
 		}
 
-		var filteredPath []string
-		for _, f := range folders {
-			f = filepath.Clean(f)
-			if strings.HasSuffix(f, "res") &&
-				wkfs.IsDir(filepath.Join(f, "values")) &&
-				wkfs.IsFile(filepath.Join(f, "values", "strings.xml")) {
-
-				filteredPath = append(filteredPath, f)
-			}
-		}
-
-		originOutputDir := outputDir
-		outputDir = ""
 		interact := viper.GetBool(flagsInteract)
-		if interact {
-			if len(filteredPath) == 0 {
-				force := false
-				prompt := &survey.Confirm{
-					Message: fmt.Sprintf("%v is not an valid output directory, process anyway?", originOutputDir),
-				}
-				err = survey.AskOne(prompt, &force)
-				if err != nil {
-					logrus.Error(err)
-					exit(1)
-				}
-				if !force {
-					logrus.Info("abort")
-					exit(0)
-				}
-			} else if len(filteredPath) > 1 && len(filteredPath) < 42 {
-				prompt := &survey.Select{
-					Message: fmt.Sprintf("there are %v available output directories", len(filteredPath)),
-					Options: filteredPath,
-				}
-				err = survey.AskOne(prompt, &outputDir)
-				if err != nil {
-					logrus.Error(err)
-					exit(1)
-				}
-			} else if len(filteredPath) == 1 {
-				outputDir = filteredPath[0]
-			} else {
-				logrus.Errorf("too many candidate output directories(%v), abort", len(filteredPath))
-				exit(1)
-			}
-		} else {
-			if len(filteredPath) == 0 {
-				logrus.Errorf("the output might not be an android resource directory")
-				logrus.Info("you might want to run the command again with --interact option")
-				exit(1)
-			} else if len(filteredPath) > 1 {
-				logrus.Errorf("there are multiple android resource directories")
-				for _, v := range filteredPath {
-					logrus.Info(v)
-				}
-				logrus.Info("you might want to run the command again with --interact option")
-				exit(1)
-			} else {
-				outputDir = filteredPath[0]
-			}
-		}
-
-		if outputDir == "" {
-			logrus.Error("no available output directory, abort")
-			exit(1)
+		routesPath := viper.GetString(flagsRoutes)
+		sourceSet := viper.GetString(flagsSourceSet)
+		if routesPath == "" {
+			outputDir = selectOutputDir(outputDir, targetFile, sourceSet, interact)
 		}
 
-		var valueFolders []string
-		_, valueFolders, err = wkfs.Scan(outputDir, wkfs.WithFoldersOnly(), wkfs.WithPatterns("values"))
+		// language of the plain values folder
+		var baseLocale locale.Locale
+		baseLocale, err = locale.Parse(viper.GetString(flagsBaseLanguage))
 		if err != nil {
-			logrus.Errorf("cannot walk through output directory %v, err:%v", outputDir, err)
+			logrus.Errorf("invalid base language, err:%v", err)
 			exit(1)
 		}
 
-		filteredPath = []string{}
-		for _, f := range valueFolders {
-			if wkfs.IsFile(filepath.Join(f, "strings.xml")) {
-				filteredPath = append(filteredPath, f)
+		// qualifier sets to target besides the default configuration
+		qualifierSets := map[string]bool{"": true}
+		for _, v := range viper.GetStringSlice(flagsQualifiers) {
+			if v == "*" {
+				qualifierSets[v] = true
+				continue
 			}
-		}
-
-		lang2stringFolders := make(map[string]string)
-		for _, v := range filteredPath {
-			base := filepath.Base(v)
-			if strings.EqualFold(base, "values") {
-				// en
-				lang2stringFolders["en"] = v
-			} else {
-				i := strings.IndexRune(base, '-')
-				if i < 0 {
-					continue
-				}
-				lang := base[i+1:]
-				lang2stringFolders[lang] = v
+			var set string
+			set, err = resdir.NormalizeQualifierSet(v)
+			if err != nil {
+				logrus.Errorf("invalid qualifier set, err:%v", err)
+				exit(1)
 			}
+			qualifierSets[set] = true
 		}
 
 		// STEP 3. load all source files
+		runReport.Begin("load")
 		logrus.Info("loading source files...")
 		allSources := make(map[string]*model.SourceFile)
 		var collisionResolver parser.CollisionResolver
@@ -239,27 +223,42 @@ var appendCmd = &cobra.Command{
 				logrus.Errorf("cannot load source csv file %v, err:%v", v, err)
 				exit(1)
 			}
+			source.ExpandArrays(viper.GetString(flagsArrayDelimiter))
 			allSources[v] = source
+
+			languages := make([]string, 0, len(source.Languages))
+			for lang := range source.Languages {
+				languages = append(languages, lang)
+			}
+			sort.Strings(languages)
+			runReport.AddSource(&report.Source{Path: v, Languages: languages, Keys: len(source.Names)})
 		}
 
-		if viper.GetBool(flagsNoLint) {
+		noLint := viper.GetBool(flagsNoLint)
+		lintFailed := func() {
+			lint.writeOutput()
+			logrus.Warnf("fix errors before continue, downgrade their rules in the config file, or add '--%v' flag", flagsNoLint)
+			if check {
+				exit(exitLintFailed)
+			}
+			finishReport(report.StatusLintFailed)
+		}
+		if noLint {
 			logrus.Info("flag 'nolint' specified, skip linting...")
 		} else {
 			// lint
+			runReport.Begin("lint")
 			logrus.Info("linting...")
-			sanitized := true
+			lintErrors := 0
 			for _, source := range allSources {
-				lintResult := source.Lint(model.WithDefaultLinters())
+				lintResult := lint.filter(source.Lint(model.WithDefaultLinters()), source.Ignores)
 				if len(lintResult) != 0 {
-					sanitized = false
 					logrus.Warnf("%v found %d issues", source.AbsPath, len(lintResult))
-					for _, lint := range lintResult {
-						logrus.Warnf("%v", lint.Desc)
-					}
+					lintErrors += logLint(lintResult)
 				}
 			}
-			if !sanitized {
-				logrus.Warn("fix issues before continue, or add '--nolint' flag")
+			if lintErrors != 0 && !updateBaseline {
+				lintFailed()
 				return
 			}
 		}
@@ -309,20 +308,73 @@ var appendCmd = &cobra.Command{
 			}
 		}
 
+		if runReport != nil {
+			// record collisions, the first value wins without a resolver
+			resolve := mergeResolver
+			mergeResolver = func(collision *model.Collision) string {
+				value := collision.Values[0]
+				if resolve != nil {
+					value = resolve(collision)
+				}
+				runReport.AddMergeCollision(&report.MergeCollision{
+					Language: collision.Language,
+					Key:      collision.Key,
+					Files:    collision.Files,
+					Values:   collision.Values,
+					Value:    value,
+				})
+				return value
+			}
+		}
+
+		// sorted by path, for --order source and group
+		runReport.Begin("merge")
+		srcPaths := make([]string, 0, len(allSources))
+		for v := range allSources {
+			srcPaths = append(srcPaths, v)
+		}
+		sort.Strings(srcPaths)
 		srcModelList := make([]*model.SourceFile, 0, len(allSources))
-		for _, src := range allSources {
-			srcModelList = append(srcModelList, src)
+		for _, v := range srcPaths {
+			srcModelList = append(srcModelList, allSources[v])
+		}
+		// columns and files of a single flavor like en@huawei go to its overlay
+		merged, flavored := model.SplitFlavors(model.Merge(srcModelList, mergeResolver))
+		attributes := model.MergeAttributes(srcModelList)
+		flavors := make([]string, 0, len(flavored))
+		for flavor := range flavored {
+			flavors = append(flavors, flavor)
+		}
+		sort.Strings(flavors)
+		allData := []map[string]map[string]string{merged}
+		for _, flavor := range flavors {
+			allData = append(allData, flavored[flavor])
+		}
+
+		for _, data := range allData {
+			if arrayErrors := model.CheckArrays(data); len(arrayErrors) != 0 {
+				for _, e := range arrayErrors {
+					logrus.Error(e.Desc)
+				}
+				logrus.Error("fix string-array issues before continue")
+				// issues of the sources, not a tool error
+				if check {
+					exit(exitLintFailed)
+				}
+				exit(1)
+			}
 		}
-		merged := model.Merge(srcModelList, mergeResolver)
 
 		// unescape
 		if viper.GetBool(flagsNoEscape) {
 			logrus.Info("flag 'noescape' specified, skip escaping")
 		} else {
 			logrus.Info("escaping...")
-			for _, kvs := range merged {
-				for k, v := range kvs {
-					kvs[k] = model.EscapeString(v)
+			for _, data := range allData {
+				for _, kvs := range data {
+					for k, v := range kvs {
+						kvs[k] = escape.Android.Escape(escape.SourceText(v))
+					}
 				}
 			}
 		}
@@ -330,9 +382,11 @@ var appendCmd = &cobra.Command{
 		// auto placeholder
 		if viper.GetBool(flagsAutoPlaceHolder) {
 			logrus.Info("processing auto placeholder...")
-			for _, kvs := range merged {
-				for k, v := range kvs {
-					kvs[k] = model.AutoPlaceholder(v)
+			for _, data := range allData {
+				for _, kvs := range data {
+					for k, v := range kvs {
+						kvs[k] = model.AutoPlaceholder(v)
+					}
 				}
 			}
 		}
@@ -353,7 +407,7 @@ var appendCmd = &cobra.Command{
 				for i := 0; i < mapSize; i++ {
 					k := keyMappingKeys[i]
 					v := keyMappingAlias[i]
-					keyMappingMap[k] = v
+					keyMappingMap[locale.Normalize(k)] = locale.Normalize(v)
 				}
 			}
 		}
@@ -381,7 +435,7 @@ var appendCmd = &cobra.Command{
 					exit(1)
 				}
 				for _, item := range mappingCfg.Mapping {
-					keyMappingMap[item.Key] = item.Alias
+					keyMappingMap[locale.Normalize(item.Key)] = locale.Normalize(item.Alias)
 				}
 			}
 		}
@@ -392,8 +446,95 @@ var appendCmd = &cobra.Command{
 		} else {
 			logrus.Info("no key mapping")
 		}
+		{
+			// from built-in language names, explicit mapping takes precedence
+			seen := make(map[string]bool)
+			var headers []string
+			for _, data := range allData {
+				for lang := range data {
+					if !seen[lang] {
+						seen[lang] = true
+						headers = append(headers, lang)
+					}
+				}
+			}
+			sort.Strings(headers)
+			for _, lang := range headers {
+				if _, ok := keyMappingMap[lang]; ok {
+					continue
+				}
+				if _, err := locale.Parse(lang); err == nil {
+					continue
+				}
+				if l, ok := locale.LookupName(lang); ok {
+					keyMappingMap[lang] = l.String()
+					logrus.Infof("language name %v -> %v", lang, l)
+					continue
+				}
+				if suggestions := locale.SuggestNames(lang, 3); len(suggestions) > 0 {
+					logrus.Warnf("unknown language %v, did you mean %v?", lang, strings.Join(suggestions, ", "))
+				} else {
+					logrus.Warnf("unknown language %v, map it with --%v or --%v", lang, flagsKey, flagsKeyMappingConfig)
+				}
+			}
+		}
+
+		if !noLint {
+			// placeholders of every language against the base language
+			var lintResult []*model.LintResult
+			for i, data := range allData {
+				languages := make([]string, 0, len(data))
+				for lang := range data {
+					languages = append(languages, lang)
+				}
+				sort.Strings(languages)
+				base := ""
+				for _, lang := range languages {
+					outputLang := lang
+					if v, ok := keyMappingMap[lang]; ok {
+						outputLang = v
+					}
+					if l, err := locale.Parse(outputLang); err == nil && l.String() == baseLocale.String() {
+						base = lang
+						break
+					}
+				}
+				if base == "" {
+					continue
+				}
+				keyResult := model.LintKeys(data, model.LintPlaceholders(base, data[base]))
+				flavor := ""
+				if i > 0 {
+					flavor = flavors[i-1]
+				}
+				model.LocateResults(srcModelList, flavor, keyResult)
+				lintResult = append(lintResult, keyResult...)
+			}
+			lintResult = lint.filter(lintResult, model.MergeIgnores(srcModelList))
+			if len(lintResult) != 0 {
+				logrus.Warnf("found %d placeholder issues", len(lintResult))
+				if logLint(lintResult) != 0 && !updateBaseline {
+					lintFailed()
+					return
+				}
+			}
+
+			lint.writeOutput()
+			if updateBaseline {
+				if err = lint.updateBaseline(); err != nil {
+					logrus.Errorf("cannot write lint baseline, err:%v", err)
+					exit(1)
+				}
+				finishReport(report.StatusOK)
+				return
+			}
+			if stale := lintBaseline.Stale(); len(stale) > 0 {
+				logrus.Infof("%d entries of %v no longer found, drop them with --%v", len(stale), lintBaseline.Path(), flagsUpdateBaseline)
+			}
+		}
 
 		// STEP 4. append to target xml files
+		runReport.Begin("append")
 
 		// collision resolve
 		var appendCollisionResolver appender.CollisionResolver
@@ -431,7 +572,7 @@ var appendCmd = &cobra.Command{
 					oldMark = "*"
 					newMark = " "
 				}
-				dir := filepath.Base(filepath.Dir(file))
+				dir := filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
 				logrus.Infof("'%v' collision in '%v' line '%d'", key, dir, pos)
 				logrus.Debugf("previous %s: %s", oldMark, old)
 				logrus.Debugf("newer    %s: %s", newMark, newer)
@@ -439,35 +580,713 @@ var appendCmd = &cobra.Command{
 				return result
 			}
 		}
+		if runReport != nil {
+			resolve := appendCollisionResolver
+			appendCollisionResolver = func(file string, pos int, key, old, newer string) string {
+				value := resolve(file, pos, key, old, newer)
+				runReport.AddCollision(file, pos, key, old, newer, value)
+				return value
+			}
+		}
 
 		// dry run
-		dry := viper.GetBool(flagsDry)
+		dry := viper.GetBool(flagsDry) || check
+		createMissing := viper.GetBool(flagsCreateMissing)
+		managed := viper.GetBool(flagsManaged)
+		order, err := appender.ParseOrder(viper.GetString(flagsOrder))
+		if err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+		if managed && order != appender.OrderSorted && order != appender.OrderSource {
+			logrus.Errorf("--%v %v cannot be used with --%v, the managed block is either sorted or in source order", flagsOrder, order, flagsManaged)
+			exit(1)
+		}
+		keyOrder := model.MergeOrder(srcModelList)
+		duplicateHandler := func(key string, locations []resdir.Location) {
+			where := make([]string, 0, len(locations))
+			for _, loc := range locations {
+				where = append(where, loc.String())
+			}
+			logrus.Warnf("duplicate key '%v' defined in %v", key, strings.Join(where, ", "))
+		}
+
+		// modules to append to, the single output res directory unless routed,
+		// along with the overlays of flavors
+		type appendModule struct {
+			res    string
+			data   map[string]map[string]string
+			flavor string
+		}
+		var routes *router.Config
+		if routesPath != "" {
+			routes, err = router.Load(routesPath)
+			if err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+		}
+		sourceModules := model.MergeModules(srcModelList)
+		// resources left out by routing are never pruned
+		unrouted := make(map[string]bool)
+		routeData := func(data map[string]map[string]string) (resources []string, routed map[string]map[string]map[string]string) {
+			if routes == nil {
+				return []string{outputDir}, map[string]map[string]map[string]string{outputDir: data}
+			}
+			result := routes.Route(data, sourceModules)
+			for _, name := range result.Unmatched {
+				logrus.Warnf("key '%v' matches no route, skipped", name)
+				unrouted[name] = true
+			}
+			ambiguous := make([]string, 0, len(result.Ambiguous))
+			for name := range result.Ambiguous {
+				ambiguous = append(ambiguous, name)
+			}
+			sort.Strings(ambiguous)
+			for _, name := range ambiguous {
+				var rules []string
+				for _, r := range result.Ambiguous[name] {
+					rules = append(rules, fmt.Sprintf("%v -> %v", r, r.Res))
+				}
+				logrus.Warnf("key '%v' matches routes to several res directories, skipped: %v", name, strings.Join(rules, ", "))
+				unrouted[name] = true
+			}
+			routed = make(map[string]map[string]map[string]string)
+			for _, res := range result.Resources() {
+				dir := res
+				if !filepath.IsAbs(dir) {
+					dir = filepath.Join(outputDir, dir)
+				}
+				dir = resolveResDir(dir)
+				if sourceSet != resdir.MainSourceSet {
+					dir, err = resdir.SourceSetRes(dir, sourceSet)
+					if err != nil {
+						logrus.Errorf("cannot find source set %v of route target, %v", sourceSet, err)
+						exit(1)
+					}
+				} else if !wkfs.IsDir(dir) {
+					logrus.Errorf("route target %v is not a directory", dir)
+					exit(1)
+				}
+				resources = append(resources, dir)
+				routed[dir] = result.Routed[res]
+				logrus.Infof("%d key(s) routed to %v", len(resourceNames(result.Routed[res])), dir)
+			}
+			return
+		}
+		var modules []*appendModule
+		resources, routed := routeData(merged)
+		for _, res := range resources {
+			modules = append(modules, &appendModule{res: res, data: routed[res]})
+		}
+		for _, flavor := range flavors {
+			resources, routed = routeData(flavored[flavor])
+			for _, res := range resources {
+				overlay, err := resdir.SourceSetRes(res, flavor)
+				if err != nil {
+					logrus.Errorf("cannot find the overlay of flavor %v, %v", flavor, err)
+					exit(1)
+				}
+				modules = append(modules, &appendModule{res: overlay, data: routed[res], flavor: flavor})
+			}
+		}
 
-		// merge
-		for lang, kvs := range merged {
-			outputLang := lang
-			if v, ok := keyMappingMap[lang]; ok {
-				outputLang = v
+		// prune
+		var prune *appender.Prune
+		var track string
+		if policy := viper.GetString(flagsPrune); policy != "" {
+			prune = &appender.Prune{
+				ObsoleteFile: viper.GetString(flagsObsoleteFile),
 			}
-			if xmlFolder, ok := lang2stringFolders[outputLang]; ok {
-				stringFilePath := filepath.Join(xmlFolder, "strings.xml")
-				logrus.Infof("appending to %v ...", stringFilePath)
+			prune.Policy, err = appender.ParsePrunePolicy(policy)
+			if err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+
+			track = viper.GetString(flagsTrack)
+			if track == "" {
+				track = "state"
+				if managed {
+					track = "block"
+				}
+			}
+			switch track {
+			case "block":
+				if !managed {
+					logrus.Errorf("--%v block requires --%v", flagsTrack, flagsManaged)
+					exit(1)
+				}
+			case "prefix":
+				prefixes := viper.GetStringSlice(flagsTrackPrefix)
+				if len(prefixes) == 0 {
+					logrus.Errorf("--%v prefix requires --%v", flagsTrack, flagsTrackPrefix)
+					exit(1)
+				}
+				prune.Tracked = func(tag, name string) bool {
+					for _, prefix := range prefixes {
+						if strings.HasPrefix(name, prefix) {
+							return true
+						}
+					}
+					return false
+				}
+			case "state":
+				// loaded per res directory
+			default:
+				logrus.Errorf("unknown --%v %v, expect block, prefix or state", flagsTrack, track)
+				exit(1)
+			}
+			logrus.Infof("pruning keys tracked by %v, policy %v", track, prune.Policy)
+		}
+
+		// state files by path, along with the resources they record, the ones
+		// written by this run and the ones tracked before still in sources
+		states := make(map[string]*appender.State)
+		stateResources := make(map[string]map[string]bool)
+
+		// plan
+		type appendJob struct {
+			lang    string
+			column  string
+			created bool
+			stats   *appender.Stats
+			kvs     map[string]string
+			folder  string
+			path    string
+			opts    []appender.AppendOpt
+			// state records the resources written to path
+			state string
+		}
+		var jobs []*appendJob
+		var createdLocales []string
+		var skippedLocales []string
+		for _, m := range modules {
+			lang2stringFolders := languageFolders(m.res, targetFile, baseLocale, qualifierSets)
+
+			// flavor overlays only hold the keys overridden, they are not pruned
+			var modulePrune *appender.Prune
+			var moduleState string
+			if prune != nil && m.flavor == "" {
+				keep := resourceIDs(m.data)
+				for _, kvs := range merged {
+					for key := range kvs {
+						if name, tag := resdir.KeyResource(key); unrouted[name] {
+							keep[resdir.ResourceID(tag, name)] = true
+						}
+					}
+				}
+				p := *prune
+				p.Keep = keep
+				if track == "state" {
+					statePath := viper.GetString(flagsStateFile)
+					if statePath == "" {
+						statePath = filepath.Join(filepath.Dir(m.res), ".i18n-state.json")
+					}
+					state, ok := states[statePath]
+					if !ok {
+						state, err = appender.LoadState(statePath)
+						if err != nil {
+							logrus.Error(err)
+							exit(1)
+						}
+						if len(state.Resources) == 0 {
+							logrus.Infof("no resources tracked in %v yet, they will be recorded by this run", statePath)
+						}
+						states[statePath] = state
+						stateResources[statePath] = make(map[string]bool)
+					}
+					for _, id := range state.Resources {
+						if keep[id] {
+							stateResources[statePath][id] = true
+						}
+					}
+					p.Tracked = state.Tracked
+					moduleState = statePath
+				}
+				modulePrune = &p
+			}
+
+			languages := make([]string, 0, len(m.data))
+			for lang := range m.data {
+				languages = append(languages, lang)
+			}
+			sort.Strings(languages)
+
+			var moduleJobs []*appendJob
+			visited := make(map[string]bool)
+			for _, lang := range languages {
+				kvs := m.data[lang]
+				outputLang := lang
+				if v, ok := keyMappingMap[lang]; ok {
+					outputLang = v
+				}
+				xmlFolders := lang2stringFolders[outputLang]
+				appendOpts := []appender.AppendOpt{appender.WithAttributes(attributes)}
+				created := false
+				if l, err := locale.Parse(outputLang); len(xmlFolders) == 0 && (createMissing || m.flavor != "") && err == nil {
+					xmlFolder := filepath.Join(m.res, "values")
+					if l.String() != baseLocale.String() {
+						xmlFolder = filepath.Join(m.res, "values-"+l.AndroidQualifier())
+					}
+					xmlFolders = append(xmlFolders, xmlFolder)
+					appendOpts = append(appendOpts, appender.WithCreateMissing())
+					created = true
+					createdLocales = append(createdLocales, fmt.Sprintf("%v (%v)", xmlFolder, outputLang))
+				}
+				if len(xmlFolders) > 0 {
+					for _, w := range model.CheckPluralQuantities(outputLang, kvs) {
+						logrus.Warn(w.Desc)
+					}
+				} else {
+					logrus.Infof("lang %v missing output resource folder in %v, skipped", outputLang, m.res)
+					skippedLocales = append(skippedLocales, outputLang)
+				}
+				for _, xmlFolder := range xmlFolders {
+					visited[xmlFolder] = true
+					moduleJobs = append(moduleJobs, &appendJob{
+						lang:    model.FlavorLanguage(outputLang, m.flavor),
+						column:  lang,
+						created: created,
+						kvs:     kvs,
+						folder:  xmlFolder,
+						opts:    appendOpts,
+					})
+				}
+			}
+			if modulePrune != nil {
+				// locales without source columns are pruned as well
+				folderLanguages := make([]string, 0, len(lang2stringFolders))
+				for lang := range lang2stringFolders {
+					folderLanguages = append(folderLanguages, lang)
+				}
+				sort.Strings(folderLanguages)
+				for _, lang := range folderLanguages {
+					for _, xmlFolder := range lang2stringFolders[lang] {
+						if !visited[xmlFolder] {
+							moduleJobs = append(moduleJobs, &appendJob{lang: lang, folder: xmlFolder})
+						}
+					}
+				}
+			}
+			// columns mapped to the same folder are merged into one job, a
+			// job of its own would overwrite the staged edits of the other
+			// and prune its keys
+			merged := moduleJobs[:0]
+			byPath := make(map[string]*appendJob)
+			for _, job := range moduleJobs {
+				job.path = filepath.Join(job.folder, targetFile)
+				first, ok := byPath[job.path]
+				if !ok {
+					byPath[job.path] = job
+					merged = append(merged, job)
+					continue
+				}
+				if len(job.kvs) == 0 {
+					continue
+				}
+				logrus.Warnf("columns %v and %v both write to %v, merged", first.column, job.column, job.path)
+				kvs := make(map[string]string, len(first.kvs)+len(job.kvs))
+				for k, v := range first.kvs {
+					kvs[k] = v
+				}
+				first.kvs = kvs
+				for k, v := range job.kvs {
+					if old, ok := first.kvs[k]; ok && old != v {
+						v = appendCollisionResolver(job.path, 0, k, old, v)
+					}
+					first.kvs[k] = v
+				}
+				first.created = first.created || job.created
+			}
+			moduleJobs = merged
+			// folders found by another resource file lack the target file
+			withTarget := moduleJobs[:0]
+			for _, job := range moduleJobs {
+				if !wkfs.FileExists(job.path) {
+					if !createMissing && m.flavor == "" {
+						logrus.Infof("%v not found, skipped, run with --%v to create it", job.path, flagsCreateMissing)
+						continue
+					}
+					job.opts = append(job.opts, appender.WithCreateMissing())
+				}
+				withTarget = append(withTarget, job)
+			}
+			moduleJobs = withTarget
+			for _, job := range moduleJobs {
+				job.opts = append(job.opts,
+					appender.WithDuplicateHandler(duplicateHandler),
+					appender.WithOrder(order, keyOrder))
+				if managed {
+					var idx resdir.Index
+					idx, err = resdir.IndexFolder(job.folder, job.path)
+					if err != nil {
+						logrus.Errorf("cannot index %v, err:%v", job.folder, err)
+						exit(1)
+					}
+					job.opts = append(job.opts,
+						appender.WithManagedBlock(),
+						appender.WithIndex(idx))
+				}
+				if modulePrune != nil {
+					job.opts = append(job.opts, appender.WithPrune(modulePrune))
+					job.state = moduleState
+				}
+			}
+			jobs = append(jobs, moduleJobs...)
+		}
+
+		// list stale keys before touching anything
+		if prune != nil {
+			var stale []*appender.Stale
+			for _, job := range jobs {
+				var found []*appender.Stale
+				found, err = appender.ListStale(job.path, job.opts...)
+				if err != nil {
+					logrus.Errorf("cannot list stale keys of %v, err:%v", job.path, err)
+					exit(1)
+				}
+				stale = append(stale, found...)
+			}
+			if len(stale) == 0 {
+				logrus.Info("no keys to prune")
+			} else {
+				if dry {
+					logrus.Infof("%d key(s) no longer in sources would be pruned (%v):", len(stale), prune.Policy)
+				} else {
+					logrus.Infof("%d key(s) no longer in sources will be pruned (%v):", len(stale), prune.Policy)
+				}
+				for _, v := range stale {
+					logrus.Infof("  %v %v/%v", v.Location, v.Tag, v.Key)
+				}
+				if interact && !dry {
+					confirmed := false
+					prompt := &survey.Confirm{
+						Message: fmt.Sprintf("prune %d key(s)?", len(stale)),
+					}
+					err = survey.AskOne(prompt, &confirmed)
+					if err != nil {
+						logrus.Error(err)
+						exit(1)
+					}
+					if !confirmed {
+						logrus.Info("abort")
+						exit(0)
+					}
+				}
+			}
+		}
+
+		// merge, files are staged and only written once every job succeeded
+		staged := make(map[string][]byte)
+		summary := newChangeSummary()
+		for _, job := range jobs {
+			logrus.Infof("appending to %v ...", job.path)
+
+			var stats *appender.Stats
+			job.opts = append(job.opts, appender.WithStaging(staged))
+			stats, err = appender.AppendToXML(job.kvs, job.path, appendCollisionResolver, dry, job.opts...)
+			if err != nil {
+				logrus.Errorf("cannot append to %v, nothing changed, err:%v", job.path, err)
+				exit(1)
+			}
+			logrus.Infof("%d key collisions, %d key appended", stats.Collisions, stats.Appended)
+			job.stats = stats
+			if job.state != "" {
+				for _, id := range stats.Resources {
+					stateResources[job.state][id] = true
+				}
+			}
+			summary.add(job.lang, job.created, stats)
+			runReport.AddFile(&report.File{
+				Path:      job.path,
+				Language:  job.lang,
+				Created:   job.created,
+				Added:     stats.Appended,
+				Changed:   stats.Changed,
+				Unchanged: stats.Unchanged,
+				Skipped:   stats.Skipped,
+			})
+		}
 
-				var keyCollisions, keyAppended int
-				keyCollisions, keyAppended, err = appender.AppendToXML(kvs, stringFilePath, appendCollisionResolver, dry)
+		for statePath, state := range states {
+			staged[statePath], err = state.Bytes(stateResources[statePath])
+			if err != nil {
+				logrus.Errorf("cannot save state file %v, err:%v", statePath, err)
+				exit(1)
+			}
+		}
+		summary.print()
+
+		runReport.Begin("write")
+		outOfSync := 0
+		switch {
+		case check:
+			if patch := viper.GetString(flagsPatch); patch != "" {
+				err = printDiff(staged, patch, os.Stderr)
 				if err != nil {
-					logrus.Errorf("cannot append to %v, err:%v", stringFilePath, err)
+					logrus.Errorf("cannot write diff, err:%v", err)
 					exit(1)
 				}
-				logrus.Infof("%d key collisions, %d key appended", keyCollisions, keyAppended)
+			}
+			stats := make(map[string]*appender.Stats, len(jobs))
+			for _, job := range jobs {
+				stats[job.path] = job.stats
+			}
+			// state files only record what i18n wrote
+			for statePath := range states {
+				delete(staged, statePath)
+			}
+			outOfSync, err = listOutOfSync(staged, stats)
+			if err != nil {
+				logrus.Errorf("cannot compare files, err:%v", err)
+				exit(1)
+			}
+		case dry:
+			// stdout is kept for the report
+			out := os.Stdout
+			if viper.GetString(flagsReport) == report.Stdout {
+				out = os.Stderr
+			}
+			err = printDiff(staged, viper.GetString(flagsPatch), out)
+			if err != nil {
+				logrus.Errorf("cannot write diff, err:%v", err)
+				exit(1)
+			}
+		default:
+			writeJournaled(staged)
+		}
+
+		// summary
+		if len(createdLocales) > 0 {
+			if dry {
+				logrus.Infof("%d new locale(s) would be created:", len(createdLocales))
 			} else {
-				logrus.Infof("lang %v missing output resource folder, skipped", outputLang)
+				logrus.Infof("%d new locale(s) created:", len(createdLocales))
+			}
+			for _, v := range createdLocales {
+				logrus.Infof("  %v", v)
 			}
 		}
+		if len(skippedLocales) > 0 {
+			logrus.Infof("%d locale(s) skipped for missing resource folder: %v", len(skippedLocales), strings.Join(skippedLocales, ", "))
+			logrus.Infof("run the command again with --%v to create them", flagsCreateMissing)
+		}
+
+		if runReport != nil {
+			runReport.SkippedLanguages = append(runReport.SkippedLanguages, skippedLocales...)
+		}
+		if outOfSync > 0 {
+			logrus.Errorf("%d file(s) out of sync with sources", outOfSync)
+			exit(exitOutOfSync)
+		}
+		finishReport(report.StatusOK)
 	},
 }
 
+// selectOutputDir returns the res directory of sourceSet under outputDir to
+// append to, asking for it in interactive mode when there are several. Build
+// output is skipped, res directories outside of the src/<source set>/res
+// layout are only taken for the main source set
+func selectOutputDir(outputDir, targetFile, sourceSet string, interact bool) string {
+	_, folders, err := wkfs.Scan(outputDir, wkfs.WithFoldersOnly(), wkfs.WithSkipDirs(resdir.BuildDirs...))
+	if err != nil {
+		logrus.Errorf("cannot walk through directory %v, err:%v", outputDir, err)
+		exit(1)
+	}
+
+	var filteredPath []string
+	for _, f := range folders {
+		f = filepath.Clean(f)
+		if !strings.HasSuffix(f, "res") {
+			continue
+		}
+		set, ok := resdir.SourceSet(f)
+		if (ok && set != sourceSet) || (!ok && sourceSet != resdir.MainSourceSet) {
+			logrus.Debugf("skip %v, not in source set %v", f, sourceSet)
+			continue
+		}
+		// other source sets may start empty
+		if (ok && sourceSet != resdir.MainSourceSet) ||
+			wkfs.IsDir(filepath.Join(f, "values")) && hasResourceFile(filepath.Join(f, "values"), targetFile) {
+
+			filteredPath = append(filteredPath, f)
+		}
+	}
+
+	originOutputDir := outputDir
+	outputDir = ""
+	if interact {
+		if len(filteredPath) == 0 {
+			force := false
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("%v is not an valid output directory, process anyway?", originOutputDir),
+			}
+			err = survey.AskOne(prompt, &force)
+			if err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+			if !force {
+				logrus.Info("abort")
+				exit(0)
+			}
+		} else if len(filteredPath) > 1 && len(filteredPath) < 42 {
+			prompt := &survey.Select{
+				Message: fmt.Sprintf("there are %v available output directories", len(filteredPath)),
+				Options: filteredPath,
+			}
+			err = survey.AskOne(prompt, &outputDir)
+			if err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+		} else if len(filteredPath) == 1 {
+			outputDir = filteredPath[0]
+		} else {
+			logrus.Errorf("too many candidate output directories(%v), abort", len(filteredPath))
+			exit(1)
+		}
+	} else {
+		if len(filteredPath) == 0 {
+			logrus.Errorf("the output might not be an android resource directory")
+			logrus.Info("you might want to run the command again with --interact option")
+			exit(1)
+		} else if len(filteredPath) > 1 {
+			logrus.Errorf("there are multiple android resource directories")
+			for _, v := range filteredPath {
+				logrus.Info(v)
+			}
+			logrus.Info("you might want to run the command again with --interact option")
+			exit(1)
+		} else {
+			outputDir = filteredPath[0]
+		}
+	}
+
+	if outputDir == "" {
+		logrus.Error("no available output directory, abort")
+		exit(1)
+	}
+
+	return outputDir
+}
+
+// languageFolders returns the values folders of a res directory holding or
+// able to hold targetFile, by language
+func languageFolders(outputDir, targetFile string, baseLocale locale.Locale, qualifierSets map[string]bool) map[string][]string {
+	lang2stringFolders := make(map[string][]string)
+	if !wkfs.IsDir(outputDir) {
+		// flavor overlay not created yet
+		return lang2stringFolders
+	}
+
+	_, valueFolders, err := wkfs.Scan(outputDir, wkfs.WithFoldersOnly(), wkfs.WithPatterns("values"))
+	if err != nil {
+		logrus.Errorf("cannot walk through output directory %v, err:%v", outputDir, err)
+		exit(1)
+	}
+
+	var filteredPath []string
+	for _, f := range valueFolders {
+		if hasResourceFile(f, targetFile) {
+			filteredPath = append(filteredPath, f)
+		}
+	}
+
+	for _, v := range filteredPath {
+		base := filepath.Base(v)
+		folder, err := resdir.ParseFolder(base)
+		if err != nil {
+			logrus.Warnf("skip %v, %v", v, err)
+			continue
+		}
+		if !qualifierSets["*"] && !qualifierSets[folder.QualifierSet()] {
+			logrus.Debugf("skip %v, qualifiers %v not targeted", base, folder.QualifierSet())
+			continue
+		}
+		lang := baseLocale.String()
+		if folder.HasLocale() {
+			lang = folder.Locale.String()
+		}
+		lang2stringFolders[lang] = append(lang2stringFolders[lang], v)
+	}
+
+	return lang2stringFolders
+}
+
+// resourceNames returns the resource names of data by language
+func resourceNames(data map[string]map[string]string) map[string]bool {
+	names := make(map[string]bool)
+	for _, kvs := range data {
+		for key := range kvs {
+			name, _ := model.SplitResourceKey(key)
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// resourceIDs returns the ids of the resources of data by language
+func resourceIDs(data map[string]map[string]string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, kvs := range data {
+		for key := range kvs {
+			name, tag := resdir.KeyResource(key)
+			ids[resdir.ResourceID(tag, name)] = true
+		}
+	}
+	return ids
+}
+
+// hasResourceFile reports whether a values folder holds the target file or
+// a strings.xml the target file can be added next to
+func hasResourceFile(folder, targetFile string) bool {
+	return wkfs.IsFile(filepath.Join(folder, targetFile)) ||
+		wkfs.IsFile(filepath.Join(folder, "strings.xml"))
+}
+
+// findSourceFiles returns all csv files in sources, keyed by the md5 of their absolute path
+func findSourceFiles(sources []string) map[string]string {
+	var files []string
+	for _, s := range sources {
+		if wkfs.IsFile(s) && mightBeCSVFile(s) {
+			files = append(files, s)
+		} else if wkfs.IsDir(s) {
+			csvs, _, err := wkfs.Scan(s, wkfs.WithFilesOnly(), wkfs.WithTypes("csv"))
+			if err != nil {
+				logrus.Errorf("cannot walk through directory %v, err:%v", s, err)
+				continue
+			}
+			files = append(files, csvs...)
+		}
+	}
+
+	srcFiles := make(map[string]string)
+	for _, f := range files {
+		absPath, err := filepath.Abs(f)
+		if err != nil {
+			logrus.Errorf("cannot get fullpath for %v, err:%v", f, err)
+			continue
+		}
+		sum := md5.Sum([]byte(absPath))
+		srcFiles[hex.EncodeToString(sum[:])] = absPath
+	}
+
+	return srcFiles
+}
+
 func exit(num int) {
+	switch num {
+	case 0:
+		finishReport(report.StatusOK)
+	case exitOutOfSync:
+		finishReport(report.StatusOutOfSync)
+	case exitLintFailed:
+		finishReport(report.StatusLintFailed)
+	default:
+		finishReport(report.StatusError)
+	}
 	os.Exit(num)
 }
 
@@ -498,4 +1317,27 @@ func init() {
 	appendCmd.Flags().StringSliceP(flagsKey, "k", []string{}, "key mapping sources, e.g. \"English\", \"Arabic\"")
 	appendCmd.Flags().StringSliceP(flagsAlias, "a", []string{}, "key mapping alias, e.g. \"en\", \"ar\"")
 	appendCmd.Flags().BoolP(flagsDry, "", false, "dry run, just check logic, WILL NOT write to files")
+	appendCmd.Flags().BoolP(flagsCreateMissing, "", false, "create values-<lang>/<target-file> for languages missing in the output directory")
+	appendCmd.Flags().StringP(flagsTargetFile, "", "strings.xml", "name of the resource file to append to")
+	appendCmd.Flags().BoolP(flagsManaged, "", false, "only regenerate the region between <!-- i18n:begin --> and <!-- i18n:end -->")
+	appendCmd.Flags().StringP(flagsPrune, "", "", "prune tracked keys no longer in any source, policy delete, comment or obsolete")
+	appendCmd.Flags().StringP(flagsTrack, "", "", "how pruned keys are tracked: block, prefix or state, default block with --managed, state otherwise")
+	appendCmd.Flags().StringSliceP(flagsTrackPrefix, "", nil, "key prefixes tracked with --track prefix")
+	appendCmd.Flags().StringP(flagsStateFile, "", "", "state file tracking written keys, default .i18n-state.json next to the res directory")
+	appendCmd.Flags().StringP(flagsObsoleteFile, "", "strings_obsolete.xml", "file obsolete keys are moved to, in i18n-obsolete/<values folder> next to the res directory")
+	appendCmd.Flags().StringP(flagsRoutes, "", "", "json file routing keys to the res directories of modules under the output directory")
+	appendCmd.Flags().StringP(flagsSourceSet, "", "main", "gradle source set to append to, e.g. main, debug or a flavor like huawei")
+	appendCmd.Flags().StringP(flagsJournal, "", "", "journal directory recording each run for undo, default "+journal.DefaultDir+" at the project root")
+	appendCmd.Flags().StringP(flagsPatch, "", "", "with --dry, write the changes as a patch file instead of printing them")
+	appendCmd.Flags().StringP(flagsReport, "", "", "write a json report of the run to the file, - writes it to stdout and logs to stderr")
+	appendCmd.Flags().BoolP(flagsCheck, "", false, "check the res directory is in sync with the sources without writing, exit with 2 if not, 3 on lint issues")
+	appendCmd.Flags().BoolP(flagsLintRules, "", false, "list lint rules with their severities in the config file and exit")
+	appendCmd.Flags().StringP(flagsLintBaseline, "", baseline.DefaultPath, "lint baseline file, issues in it do not fail the run")
+	appendCmd.Flags().BoolP(flagsUpdateBaseline, "", false, "write the lint issues found to the lint baseline file and exit")
+	appendCmd.Flags().StringP(flagsLintFormat, "", "", "write lint issues in a format for code review and CI: sarif, junit, checkstyle or json")
+	appendCmd.Flags().StringP(flagsLintOutput, "", report.Stdout, "file lint issues are written to with --lint-format, - for stdout")
+	appendCmd.Flags().StringP(flagsOrder, "", "sorted", "where new keys go: sorted, source, nearest, group or resort")
+	appendCmd.Flags().StringSliceP(flagsQualifiers, "", nil, "qualifier sets to append to besides default ones, e.g. night,sw600dp-land, * for all")
+	appendCmd.Flags().StringP(flagsBaseLanguage, "", "en", "language of the plain values folder")
+	appendCmd.Flags().StringP(flagsArrayDelimiter, "", "|", "delimiter of string-array items in a single cell, for keys like name[]")
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/master-g/i18n/internal/i18n"
+	"github.com/master-g/i18n/pkg/bundle"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "pack translate text into a binary string bundle.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindFlag(cmd, "src")
+		bindFlag(cmd, "out")
+		bindFlag(cmd, flagsCompress)
+		bindFlag(cmd, flagsNoMeta)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		compression, err := bundle.ParseCompression(viper.GetString(flagsCompress))
+		if err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+
+		output := viper.GetString("out")
+		if output == "" {
+			logrus.Error("output file missing")
+			exit(1)
+		}
+
+		srcFiles := findSourceFiles(viper.GetStringSlice("src"))
+		if len(srcFiles) == 0 {
+			logrus.Error("no source file found")
+			exit(1)
+		}
+
+		csvFiles := make(map[string]string)
+		for _, f := range srcFiles {
+			csvFiles[f] = filepath.Base(f)
+		}
+
+		converter := i18n.NewConverter(&i18n.Config{
+			HasMeta: !viper.GetBool(flagsNoMeta),
+		})
+		err = converter.Load(csvFiles)
+		if err != nil {
+			logrus.Errorf("cannot load source files, err:%v", err)
+			exit(1)
+		}
+
+		var outFile *os.File
+		outFile, err = os.Create(output)
+		if err != nil {
+			logrus.Errorf("cannot create %v, err:%v", output, err)
+			exit(1)
+		}
+		err = converter.WriteBundle(outFile, compression)
+		if closeErr := outFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			// exit skips deferred calls, do not leave a partial bundle behind
+			_ = os.Remove(output)
+			logrus.Errorf("cannot write bundle %v, err:%v", output, err)
+			exit(1)
+		}
+		logrus.Infof("%d language(s) written to %v", len(converter.Strings), output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+
+	bundleCmd.Flags().StringSliceP("src", "s", []string{}, "source csv file/directories")
+	bundleCmd.Flags().StringP("out", "o", "", "output bundle file")
+	bundleCmd.Flags().StringP(flagsCompress, "", "none", "string table compression, none, deflate or zstd")
+	bundleCmd.Flags().BoolP(flagsNoMeta, "", false, "do not write metadata into bundle header")
+}
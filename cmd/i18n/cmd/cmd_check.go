@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/master-g/i18n/internal/appender"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exit codes of check, 1 is a tool error
+const (
+	exitOutOfSync  = 2
+	exitLintFailed = 3
+)
+
+// checkCmd is append --check
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "check the res directory is in sync with the sources, same as append --check.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		appendCmd.PreRun(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.Set(flagsCheck, true)
+		appendCmd.Run(cmd, args)
+	},
+}
+
+// listOutOfSync logs the files whose staged content differs from the current
+// one, or with keys different from the sources, along with the keys added or
+// collided, stats are by target file
+func listOutOfSync(staged map[string][]byte, stats map[string]*appender.Stats) (outOfSync int, err error) {
+	paths := make(map[string]bool)
+	for path, content := range staged {
+		var current []byte
+		current, err = ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return
+		}
+		err = nil
+		if !bytes.Equal(current, content) {
+			paths[path] = true
+		}
+	}
+	for path, v := range stats {
+		if v != nil && (v.Appended > 0 || v.Collisions > 0) {
+			paths[path] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	for _, path := range sorted {
+		logrus.Warnf("%v is out of sync", path)
+		v := stats[path]
+		if v == nil || (v.Appended == 0 && v.Collisions == 0) {
+			logrus.Warn("  content would change, by pruning, ordering or attributes")
+			continue
+		}
+		for _, key := range v.AddedKeys {
+			logrus.Warnf("  + %v missing", key)
+		}
+		for _, key := range v.CollidedKeys {
+			logrus.Warnf("  ~ %v differs from sources", key)
+		}
+	}
+
+	if len(sorted) == 0 {
+		logrus.Info("res directory is in sync with sources")
+	}
+	return len(sorted), nil
+}
+
+func init() {
+	// flags are shared with append, defined in its init
+	checkCmd.Flags().AddFlagSet(appendCmd.Flags())
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/master-g/i18n/internal/appender"
+)
+
+// writeTree writes files under root, by slash separated relative path
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListOutOfSync(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"values/strings.xml":    "<resources>\n    <string name=\"a\">A</string>\n</resources>\n",
+		"values-fr/strings.xml": "<resources>\n    <string name=\"a\">A fr</string>\n</resources>\n",
+		"values-de/strings.xml": "<resources>\n    <string name=\"a\">A de</string>\n</resources>\n",
+	})
+	path := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+
+	tests := []struct {
+		name   string
+		staged map[string][]byte
+		stats  map[string]*appender.Stats
+		want   int
+	}{
+		{
+			name:   "in sync",
+			staged: map[string][]byte{path("values/strings.xml"): []byte("<resources>\n    <string name=\"a\">A</string>\n</resources>\n")},
+			stats:  map[string]*appender.Stats{path("values/strings.xml"): {Unchanged: 1}},
+		},
+		{
+			name:   "content changed",
+			staged: map[string][]byte{path("values-fr/strings.xml"): []byte("<resources>\n</resources>\n")},
+			want:   1,
+		},
+		{
+			name:   "new file",
+			staged: map[string][]byte{path("values-ja/strings.xml"): []byte("<resources>\n</resources>\n")},
+			want:   1,
+		},
+		{
+			name: "keys kept on collision",
+			stats: map[string]*appender.Stats{
+				path("values-fr/strings.xml"): {Collisions: 1, Skipped: 1, CollidedKeys: []string{"a"}},
+				path("values-de/strings.xml"): {Appended: 1, AddedKeys: []string{"b"}},
+				path("values/strings.xml"):    nil,
+			},
+			want: 2,
+		},
+		{
+			name:   "counted once",
+			staged: map[string][]byte{path("values-de/strings.xml"): []byte("<resources>\n</resources>\n")},
+			stats:  map[string]*appender.Stats{path("values-de/strings.xml"): {Appended: 1, AddedKeys: []string{"b"}}},
+			want:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listOutOfSync(tt.staged, tt.stats)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("listOutOfSync() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// checkArgsEnv holds the arguments of the check run in a child process
+const checkArgsEnv = "I18N_TEST_CHECK_ARGS"
+
+func TestCheckExitCodes(t *testing.T) {
+	if args := os.Getenv(checkArgsEnv); args != "" {
+		// child process, exit codes come from os.Exit
+		rootCmd.SetArgs(strings.Split(args, "\n"))
+		Execute()
+		os.Exit(0)
+	}
+
+	res := map[string]string{
+		"res/values/strings.xml":    "<resources>\n    <string name=\"title\">Title</string>\n</resources>\n",
+		"res/values-fr/strings.xml": "<resources>\n    <string name=\"title\">Titre</string>\n</resources>\n",
+	}
+	tests := []struct {
+		name string
+		csv  string
+		want int
+	}{
+		{name: "in sync", csv: "keys,en,fr\ntitle,Title,Titre\n", want: 0},
+		{name: "missing key", csv: "keys,en,fr\ntitle,Title,Titre\nok,OK,OK\n", want: exitOutOfSync},
+		{name: "changed value", csv: "keys,en,fr\ntitle,Title,Le titre\n", want: exitOutOfSync},
+		{name: "lint error", csv: "keys,en,fr\ntitle,Title,Titre %d\n", want: exitLintFailed},
+		{name: "string-array gap", csv: "keys,en,fr\ntitle,Title,Titre\ndays[0],Mon,Lun\ndays[2],Wed,Mer\n", want: exitLintFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, res)
+			writeTree(t, root, map[string]string{"i18n/strings.csv": tt.csv})
+			before := make(map[string]string)
+			for name := range res {
+				raw, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				before[name] = string(raw)
+			}
+
+			args := []string{"check", "--src", filepath.Join(root, "i18n"), "--out", filepath.Join(root, "res")}
+			cmd := exec.Command(os.Args[0], "-test.run=^TestCheckExitCodes$")
+			// HOME keeps the config file of the user out
+			cmd.Env = append(os.Environ(), checkArgsEnv+"="+strings.Join(args, "\n"), "HOME="+root)
+			out, err := cmd.CombinedOutput()
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.want {
+				t.Errorf("check exit code = %d, want %d, output:\n%s", code, tt.want, out)
+			}
+
+			// check never writes
+			for name, content := range before {
+				raw, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+				if err != nil || string(raw) != content {
+					t.Errorf("check changed %v", name)
+				}
+			}
+		})
+	}
+}
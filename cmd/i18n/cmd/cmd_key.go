@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/master-g/i18n/internal/journal"
+	"github.com/master-g/i18n/internal/keyops"
+	"github.com/master-g/i18n/pkg/wkfs"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "rename, delete, move or copy string keys across all locales of a res directory.",
+}
+
+var keyRenameCmd = &cobra.Command{
+	Use:   "rename [old new]",
+	Short: "rename a key in every values folder, optionally rewriting references",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 && !(len(args) == 0 && cmd.Flags().Changed(flagsMapping)) {
+			return fmt.Errorf("expect old and new key names, or --%v", flagsMapping)
+		}
+		return nil
+	},
+	PreRun: bindKeyFlags,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := openKeyWorkspace(viper.GetString(flagsRes))
+
+		renames := make([]*keyops.Rename, 0)
+		if len(args) == 2 {
+			renames = append(renames, &keyops.Rename{Old: args[0], New: args[1]})
+		} else {
+			var err error
+			renames, err = loadRenameMapping(viper.GetString(flagsMapping))
+			if err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+		}
+
+		if err := keyops.CheckRenames(renames); err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+		for _, r := range renames {
+			r.Tags = w.Tags(r.Old)
+			if err := w.Rename(r.Old, r.New); err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+		}
+
+		pending := pendingOf(w)
+		changes := w.Changes()
+		if refs := viper.GetString(flagsRefs); refs != "" {
+			rewritten, err := keyops.RewriteReferences(refs, renames, pending)
+			if err != nil {
+				logrus.Errorf("cannot rewrite references in %v, err:%v", refs, err)
+				exit(1)
+			}
+			changes = append(changes, rewritten...)
+		}
+
+		commitKeyChanges(changes, pending)
+	},
+}
+
+var keyDeleteCmd = &cobra.Command{
+	Use:    "delete key...",
+	Short:  "delete keys from every values folder",
+	Args:   cobra.MinimumNArgs(1),
+	PreRun: bindKeyFlags,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := openKeyWorkspace(viper.GetString(flagsRes))
+
+		deleted := make(map[string][]string)
+		for _, key := range args {
+			deleted[key] = w.Tags(key)
+			if err := w.Delete(key); err != nil {
+				logrus.Error(err)
+				exit(1)
+			}
+		}
+
+		if refs := viper.GetString(flagsRefs); refs != "" {
+			found, err := keyops.FindReferences(refs, deleted)
+			if err != nil {
+				logrus.Errorf("cannot search references in %v, err:%v", refs, err)
+				exit(1)
+			}
+			for _, v := range found {
+				logrus.Warnf("%v: %v is still used", v.Location, v.Desc)
+			}
+		}
+
+		commitKeyChanges(w.Changes(), pendingOf(w))
+	},
+}
+
+var keyMoveCmd = &cobra.Command{
+	Use:    "move key... --to module/res",
+	Short:  "move keys to the same values folders of another res directory",
+	Args:   cobra.MinimumNArgs(1),
+	PreRun: bindKeyFlags,
+	Run: func(cmd *cobra.Command, args []string) {
+		transferKeys(args, false)
+	},
+}
+
+var keyCopyCmd = &cobra.Command{
+	Use:    "copy old new | copy key... --to module/res",
+	Short:  "copy a key to a new name, or keys to another res directory",
+	Args:   cobra.MinimumNArgs(1),
+	PreRun: bindKeyFlags,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString(flagsTo) != "" {
+			transferKeys(args, true)
+			return
+		}
+		if len(args) != 2 {
+			logrus.Errorf("expect old and new key names, or --%v", flagsTo)
+			exit(1)
+		}
+
+		w := openKeyWorkspace(viper.GetString(flagsRes))
+		if err := w.Copy(args[0], args[1]); err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+		commitKeyChanges(w.Changes(), pendingOf(w))
+	},
+}
+
+func bindKeyFlags(cmd *cobra.Command, args []string) {
+	bindFlag(cmd, flagsRes)
+	bindFlag(cmd, flagsDry)
+	bindFlag(cmd, flagsRefs)
+	bindFlag(cmd, flagsJournal)
+	if cmd.Flags().Lookup(flagsTo) != nil {
+		bindFlag(cmd, flagsTo)
+	}
+	if cmd.Flags().Lookup(flagsMapping) != nil {
+		bindFlag(cmd, flagsMapping)
+	}
+}
+
+// transferKeys moves or copies keys to the res directory given by --to
+func transferKeys(keys []string, keep bool) {
+	to := viper.GetString(flagsTo)
+	if to == "" {
+		logrus.Errorf("destination missing, use --%v", flagsTo)
+		exit(1)
+	}
+
+	src := openKeyWorkspace(viper.GetString(flagsRes))
+	dst, err := keyops.Open(resolveResDir(to))
+	if err != nil {
+		// the destination may have no values folder yet
+		dst = &keyops.Workspace{ResDir: resolveResDir(to)}
+	}
+	if filepath.Clean(src.ResDir) == filepath.Clean(dst.ResDir) {
+		logrus.Errorf("source and destination are the same res directory %v", src.ResDir)
+		exit(1)
+	}
+
+	for _, key := range keys {
+		if err = src.MoveTo(dst, key, keep); err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+	}
+
+	commitKeyChanges(append(src.Changes(), dst.Changes()...), pendingOf(src, dst))
+}
+
+// resolveResDir accepts a res directory or a module directory containing one
+func resolveResDir(path string) string {
+	for _, candidate := range []string{
+		filepath.Join(path, "src", "main", "res"),
+		filepath.Join(path, "res"),
+	} {
+		if wkfs.IsDir(candidate) {
+			return candidate
+		}
+	}
+	return path
+}
+
+func openKeyWorkspace(res string) *keyops.Workspace {
+	if res == "" {
+		logrus.Errorf("res directory missing, use --%v", flagsRes)
+		exit(1)
+	}
+	w, err := keyops.Open(resolveResDir(res))
+	if err != nil {
+		logrus.Errorf("cannot open res directory %v, err:%v", res, err)
+		exit(1)
+	}
+	return w
+}
+
+// loadRenameMapping reads a bulk rename file, e.g.
+// {"rename": [{"from": "old_key", "to": "new_key"}]}
+func loadRenameMapping(path string) (renames []*keyops.Rename, err error) {
+	var raw []byte
+	raw, err = ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read mapping file %v, err:%v", path, err)
+	}
+
+	mapping := &struct {
+		Rename []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"rename"`
+	}{}
+	err = json.Unmarshal(raw, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse mapping file %v, err:%v", path, err)
+	}
+
+	for _, v := range mapping.Rename {
+		if v.From == "" || v.To == "" {
+			return nil, fmt.Errorf("invalid rename %q -> %q in %v", v.From, v.To, path)
+		}
+		renames = append(renames, &keyops.Rename{Old: v.From, New: v.To})
+	}
+	if len(renames) == 0 {
+		err = fmt.Errorf("no rename found in %v", path)
+	}
+	return
+}
+
+// pendingOf returns the content of every file changed in the workspaces
+func pendingOf(workspaces ...*keyops.Workspace) map[string][]byte {
+	pending := make(map[string][]byte)
+	for _, w := range workspaces {
+		files, err := w.Pending()
+		if err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+		for path, content := range files {
+			pending[path] = content
+		}
+	}
+	return pending
+}
+
+// commitKeyChanges prints the changes and writes all files at once
+func commitKeyChanges(changes []*keyops.Change, pending map[string][]byte) {
+	for _, v := range changes {
+		logrus.Infof("%v: %v", v.Location, v.Desc)
+	}
+
+	if viper.GetBool(flagsDry) {
+		logrus.Infof("dry run, %d file(s) not written", len(pending))
+		return
+	}
+	writeJournaled(pending)
+}
+
+func init() {
+	rootCmd.AddCommand(keyCmd)
+	keyCmd.AddCommand(keyRenameCmd, keyDeleteCmd, keyMoveCmd, keyCopyCmd)
+
+	keyCmd.PersistentFlags().StringP(flagsRes, "r", "", "android res directory, or a module directory containing one")
+	keyCmd.PersistentFlags().BoolP(flagsDry, "", false, "dry run, print the changes, WILL NOT write to files")
+	keyCmd.PersistentFlags().StringP(flagsJournal, "", "", "journal directory recording each run for undo, default "+journal.DefaultDir+" at the project root")
+	keyCmd.PersistentFlags().StringP(flagsRefs, "", "", "source directory to rewrite R.string.key and @string/key references in")
+
+	keyRenameCmd.Flags().StringP(flagsMapping, "", "", "json file of bulk renames, {\"rename\": [{\"from\": \"old\", \"to\": \"new\"}]}")
+	keyMoveCmd.Flags().StringP(flagsTo, "", "", "destination res directory, or a module directory containing one")
+	keyCopyCmd.Flags().StringP(flagsTo, "", "", "destination res directory, or a module directory containing one")
+}
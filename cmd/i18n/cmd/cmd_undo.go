@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/master-g/i18n/internal/journal"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "restore the files changed by a run, the latest one by default.",
+	Args:  cobra.MaximumNArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindFlag(cmd, flagsJournal)
+		bindFlag(cmd, flagsList)
+		bindFlag(cmd, flagsForce)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := journalDir(".")
+
+		if viper.GetBool(flagsList) {
+			runs, err := journal.List(dir)
+			if err != nil {
+				logrus.Errorf("cannot read journal %v, err:%v", dir, err)
+				exit(1)
+			}
+			if len(runs) == 0 {
+				logrus.Infof("no run recorded in %v", dir)
+			}
+			for _, run := range runs {
+				state := ""
+				if run.Undone {
+					state = " (undone)"
+				}
+				logrus.Infof("%v %d file(s)%v: %v", run.ID, len(run.Files), state, run.Command)
+			}
+			return
+		}
+
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		}
+		run, err := journal.Find(dir, id)
+		if err != nil {
+			logrus.Error(err)
+			exit(1)
+		}
+		err = run.Undo(viper.GetBool(flagsForce))
+		if err != nil {
+			logrus.Errorf("cannot undo run %v, err:%v", run.ID, err)
+			logrus.Infof("run with --%v to restore anyway", flagsForce)
+			exit(1)
+		}
+		for _, f := range run.Files {
+			if f.Existed {
+				logrus.Infof("restored %v", f.Path)
+			} else {
+				logrus.Infof("removed %v", f.Path)
+			}
+		}
+		logrus.Infof("run %v undone", run.ID)
+	},
+}
+
+// journalDir returns the --journal folder, or the journal of the project
+// holding start if it is not given
+func journalDir(start string) string {
+	if dir := viper.GetString(flagsJournal); dir != "" {
+		return dir
+	}
+	dir, err := journal.Locate(start)
+	if err != nil {
+		logrus.Errorf("cannot locate journal, err:%v", err)
+		exit(1)
+	}
+	return dir
+}
+
+// commonDir returns the deepest folder holding every file
+func commonDir(files map[string][]byte) string {
+	common := ""
+	for path := range files {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return "."
+		}
+		if common == "" {
+			common = dir
+			continue
+		}
+		for common != dir && !strings.HasPrefix(dir, common+string(filepath.Separator)) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	if common == "" {
+		return "."
+	}
+	return common
+}
+
+// writeJournaled writes all files at once and records them in the journal
+// of the project holding them
+func writeJournaled(files map[string][]byte) {
+	dir := journalDir(commonDir(files))
+	run, err := journal.Write(dir, files, strings.Join(os.Args, " "))
+	if err != nil && run != nil {
+		logrus.Errorf("cannot write files, %d file(s) changed, err:%v", len(run.Files), err)
+		logrus.Infof("run `i18n undo %v` to revert", run.ID)
+		exit(1)
+	} else if err != nil {
+		logrus.Errorf("cannot write files, nothing changed, err:%v", err)
+		exit(1)
+	}
+	if len(run.Files) == 0 {
+		logrus.Info("no file changed")
+		return
+	}
+	logrus.Infof("%d file(s) written, run `i18n undo %v` to revert", len(run.Files), run.ID)
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().StringP(flagsJournal, "", "", "journal directory recording each run, default "+journal.DefaultDir+" at the project root")
+	undoCmd.Flags().BoolP(flagsList, "", false, "list recorded runs")
+	undoCmd.Flags().BoolP(flagsForce, "", false, "restore files even if they changed since the run")
+}
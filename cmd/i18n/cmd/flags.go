@@ -11,4 +11,35 @@ const (
 	flagsAlias            = "alias"
 	flagsKeyMappingConfig = "key-mapping-config"
 	flagsDry              = "dry"
+	flagsCompress         = "compress"
+	flagsNoMeta           = "nometa"
+	flagsArrayDelimiter   = "array-delimiter"
+	flagsCreateMissing    = "create-missing"
+	flagsQualifiers       = "qualifiers"
+	flagsBaseLanguage     = "base-language"
+	flagsTargetFile       = "target-file"
+	flagsManaged          = "managed"
+	flagsPrune            = "prune"
+	flagsTrack            = "track"
+	flagsTrackPrefix      = "track-prefix"
+	flagsStateFile        = "state-file"
+	flagsObsoleteFile     = "obsolete-file"
+	flagsRes              = "res"
+	flagsTo               = "to"
+	flagsRefs             = "refs"
+	flagsMapping          = "mapping"
+	flagsOrder            = "order"
+	flagsRoutes           = "routes"
+	flagsSourceSet        = "source-set"
+	flagsJournal          = "journal"
+	flagsList             = "list"
+	flagsForce            = "force"
+	flagsPatch            = "patch"
+	flagsReport           = "report"
+	flagsCheck            = "check"
+	flagsLintRules        = "lint-rules"
+	flagsLintBaseline     = "lint-baseline"
+	flagsUpdateBaseline   = "update-lint-baseline"
+	flagsLintFormat       = "lint-format"
+	flagsLintOutput       = "lint-output"
 )
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/master-g/i18n/internal/baseline"
+	"github.com/master-g/i18n/internal/buildinfo"
+	"github.com/master-g/i18n/internal/lintformat"
+	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/report"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// configLintRules is the key of the lint rules in the config file
+const configLintRules = "lint.rules"
+
+// loadLintRules returns the lint rules configured in the config file
+func loadLintRules() (rules *model.RuleSet, err error) {
+	configs := make(map[string]*model.RuleConfig)
+	// yaml reads an unquoted off as false
+	offHook := viper.DecodeHook(func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		if to == reflect.TypeOf(model.SeverityOff) && from.Kind() == reflect.Bool && !data.(bool) {
+			return model.SeverityOff, nil
+		}
+		return data, nil
+	})
+	if err = viper.UnmarshalKey(configLintRules, &configs, offHook); err != nil {
+		return nil, fmt.Errorf("cannot parse %v of config file, err:%v", configLintRules, err)
+	}
+	rules, err = model.NewRuleSet(configs)
+	if err != nil {
+		return
+	}
+	unknown := rules.UnknownLanguages()
+	ids := make([]string, 0, len(unknown))
+	for id := range unknown {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		logrus.Warnf("lint rule %v: unknown languages %v, they only match source columns of the same name", id, strings.Join(unknown[id], ", "))
+	}
+	return
+}
+
+// printLintRules lists the lint rules with their configured severities
+func printLintRules(rules *model.RuleSet) {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "rule\tseverity\tdefault\tdescription")
+	for _, rule := range model.Rules {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", rule.ID, rules.Severity(rule.ID), rule.Severity, rule.Desc)
+	}
+	_ = w.Flush()
+
+	for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n") {
+		logrus.Info(line)
+	}
+}
+
+// logLint logs lint results and adds them to the report, it returns the
+// number of errors
+func logLint(results []*model.LintResult) (errors int) {
+	for _, lint := range results {
+		msg := fmt.Sprintf("[%v] %v: %v", lint.Severity, lint.Rule, lint.Desc)
+		if lint.Line > 0 {
+			msg += fmt.Sprintf(" (%v:%d:%d)", filepath.Base(lint.File), lint.Line, lint.Column)
+		}
+		if lint.Severity == model.SeverityInfo {
+			logrus.Info(msg)
+		} else {
+			logrus.Warn(msg)
+		}
+		runReport.AddLint(&report.LintFinding{
+			File:     lint.File,
+			Line:     lint.Line,
+			Column:   lint.Column,
+			Rule:     lint.Rule,
+			Severity: string(lint.Severity),
+			Language: lint.Language,
+			Key:      lint.Key,
+			Message:  lint.Desc,
+		})
+	}
+	return model.CountErrors(results)
+}
+
+// lintFilter drops lint results of rules turned off, ignored by the sources
+// or in the baseline
+type lintFilter struct {
+	rules    *model.RuleSet
+	baseline *baseline.Baseline
+	// found holds the results not ignored by the sources, baseline ones
+	// included, to update the baseline
+	found []*model.LintResult
+	// reported holds the results left, for --lint-format
+	reported []*model.LintResult
+}
+
+func (f *lintFilter) filter(results []*model.LintResult, ignores model.Ignores) []*model.LintResult {
+	results, ignored := ignores.Suppress(f.rules.Apply(results))
+	f.found = append(f.found, results...)
+	results, baselined, expired := f.baseline.Suppress(results, time.Now())
+	for _, e := range expired {
+		logrus.Warnf("baseline entry of %v expired on %v, lang:%v, key:%v", e.Rule, f.baseline.ExpiresOn(e), e.Language, e.Key)
+	}
+	if n := len(ignored) + len(baselined); n > 0 {
+		logrus.Debugf("%d lint issues suppressed, %d by the sources, %d by %v", n, len(ignored), len(baselined), f.baseline.Path())
+		runReport.AddSuppressed(n)
+	}
+	f.reported = append(f.reported, results...)
+	return results
+}
+
+// writeOutput writes the results reported in --lint-format, if specified
+func (f *lintFilter) writeOutput() {
+	format := viper.GetString(flagsLintFormat)
+	if format == "" {
+		return
+	}
+	tool := &lintformat.Tool{
+		Name:    "i18n",
+		Version: buildinfo.Version,
+		URI:     "https://github.com/master-g/i18n",
+	}
+	tool.BaseDir, _ = os.Getwd()
+
+	path := viper.GetString(flagsLintOutput)
+	out := os.Stdout
+	if path != report.Stdout {
+		var err error
+		out, err = os.Create(path)
+		if err != nil {
+			logrus.Errorf("cannot create lint output %v, err:%v", path, err)
+			return
+		}
+		defer out.Close()
+	}
+	if err := lintformat.Write(out, format, tool, f.reported); err != nil {
+		logrus.Errorf("cannot write lint output, err:%v", err)
+	}
+}
+
+// updateBaseline writes the results found to the baseline file
+func (f *lintFilter) updateBaseline() error {
+	updated := f.baseline.Update(f.found)
+	if err := updated.Write(); err != nil {
+		return err
+	}
+	logrus.Infof("%d lint issues written to %v", len(updated.Entries), updated.Path())
+	return nil
+}
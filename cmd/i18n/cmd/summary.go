@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/master-g/i18n/internal/appender"
+	"github.com/master-g/i18n/internal/buildinfo"
+	"github.com/master-g/i18n/internal/diff"
+	"github.com/master-g/i18n/internal/report"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// runReport is the report of the current run, nil without --report
+var runReport *report.Report
+
+// startReport starts recording the run if --report is specified, logs go to
+// stderr when the report goes to stdout
+func startReport() {
+	path := viper.GetString(flagsReport)
+	if path == "" {
+		return
+	}
+	if path == report.Stdout {
+		if viper.GetBool(flagsInteract) {
+			logrus.Errorf("--%v %v cannot be used with --%v", flagsReport, report.Stdout, flagsInteract)
+			exit(1)
+		}
+		logrus.SetOutput(os.Stderr)
+	}
+	runReport = report.New(buildinfo.Version, strings.Join(os.Args, " "))
+	runReport.Dry = viper.GetBool(flagsDry) || viper.GetBool(flagsCheck)
+	logrus.AddHook(runReport)
+}
+
+// finishReport writes the report of the run, if any
+func finishReport(status report.Status) {
+	if runReport == nil {
+		return
+	}
+	r := runReport
+	runReport = nil
+	r.Finish(status)
+	if err := r.Write(viper.GetString(flagsReport)); err != nil {
+		logrus.Errorf("cannot write report, err:%v", err)
+	}
+}
+
+// changeSummary counts the changes of a run per language
+type changeSummary struct {
+	stats   map[string]*appender.Stats
+	created map[string]bool
+}
+
+func newChangeSummary() *changeSummary {
+	return &changeSummary{
+		stats:   make(map[string]*appender.Stats),
+		created: make(map[string]bool),
+	}
+}
+
+func (s *changeSummary) add(lang string, created bool, stats *appender.Stats) {
+	if _, ok := s.stats[lang]; !ok {
+		s.stats[lang] = &appender.Stats{}
+	}
+	s.stats[lang].Add(stats)
+	s.created[lang] = s.created[lang] || created
+}
+
+// print logs the summary as a table
+func (s *changeSummary) print() {
+	if len(s.stats) == 0 {
+		return
+	}
+	languages := make([]string, 0, len(s.stats))
+	for lang := range s.stats {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "language\tadded\tchanged\tunchanged\tskipped\tnew locale")
+	total := &appender.Stats{}
+	for _, lang := range languages {
+		v := s.stats[lang]
+		total.Add(v)
+		created := ""
+		if s.created[lang] {
+			created = "yes"
+		}
+		fmt.Fprintf(w, "%v\t%d\t%d\t%d\t%d\t%v\n", lang, v.Appended, v.Changed, v.Unchanged, v.Skipped, created)
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\t%d\t%d\t\n", total.Appended, total.Changed, total.Unchanged, total.Skipped)
+	_ = w.Flush()
+
+	for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n") {
+		logrus.Info(line)
+	}
+}
+
+// printDiff prints the unified diff of staged files against their current
+// content to out, or writes it to patch, paths are relative to the working
+// directory
+func printDiff(staged map[string][]byte, patch string, out *os.File) error {
+	paths := make([]string, 0, len(staged))
+	for path := range staged {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	sb := &strings.Builder{}
+	changed := 0
+	for _, path := range paths {
+		old, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			old = nil
+		} else if err != nil {
+			return err
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(cwd, abs)
+		if err != nil {
+			return err
+		}
+		text := diff.Unified(filepath.ToSlash(rel), old, staged[path])
+		if text != "" {
+			changed++
+			sb.WriteString(text)
+		}
+	}
+
+	if changed == 0 {
+		logrus.Info("dry run, no file would change")
+		return nil
+	}
+	if patch != "" {
+		err = ioutil.WriteFile(patch, []byte(sb.String()), 0644)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("dry run, %d file(s) would change, patch written to %v, apply it with `git apply %v`", changed, patch, patch)
+		return nil
+	}
+
+	text := sb.String()
+	if fi, err := out.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		text = diff.Colorize(text)
+	}
+	fmt.Fprint(out, text)
+	logrus.Infof("dry run, %d file(s) would change", changed)
+	return nil
+}
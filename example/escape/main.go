@@ -3,11 +3,18 @@ package main
 import (
 	"fmt"
 
-	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/escape"
 )
 
 func main() {
 	raw := `this is a complicate string with ', and & this, like ", >_< @me`
-	escaped := model.EscapeString(raw)
+	escaped := escape.Android.Escape(raw)
 	fmt.Println(escaped)
+
+	text, err := escape.Android.Unescape(escaped)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(text == raw)
 }
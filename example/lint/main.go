@@ -12,4 +12,24 @@ func main() {
 	for _, r := range results {
 		fmt.Println(r.Desc)
 	}
+
+	// placeholders of every language against english
+	data := map[string]map[string]string{
+		"en": {"greet": "Hello %1$s, you have %2$d messages"},
+		"fr": {"greet": "Bonjour %1$d"},
+	}
+	for _, r := range model.LintKeys(data, model.LintPlaceholders("en", data["en"])) {
+		fmt.Println(r.Severity, r.Rule, r.Desc)
+	}
+
+	// missing specifiers are errors for french only
+	rules, err := model.NewRuleSet(map[string]*model.RuleConfig{
+		model.RulePlaceholderMissing: {Severity: model.SeverityError, Languages: []string{"fr"}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, r := range rules.Apply(model.LintKeys(data, model.LintPlaceholders("en", data["en"]))) {
+		fmt.Println(r.Severity, r.Rule, r.Desc)
+	}
 }
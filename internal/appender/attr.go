@@ -0,0 +1,90 @@
+package appender
+
+import (
+	"encoding/xml"
+	"sort"
+
+	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// sourceAttrs returns the source attributes to set on a resource of the
+// file. translatable and tools:* describe the resource rather than its
+// translation, lint expects them in the default folder only, so localized
+// files only get the ones changing how the value is read
+func (a *xmlAppender) sourceAttrs(name string) map[string]string {
+	src := a.options.attributes[name]
+	if !a.localized || len(src) == 0 {
+		return src
+	}
+	localized := make(map[string]string, len(src))
+	for attr, value := range src {
+		if attr == model.AttrFormatted || attr == model.AttrProduct {
+			localized[attr] = value
+		}
+	}
+	return localized
+}
+
+// mergeAttrs applies source attributes on top of existing ones, existing
+// attributes keep their order, new ones are appended sorted by name
+func mergeAttrs(old []xml.Attr, src map[string]string) (merged []xml.Attr) {
+	merged = append(merged, old...)
+
+	names := make([]string, 0, len(src))
+	for name := range src {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := src[name]
+		found := false
+		for i := range merged {
+			if resxml.AttrName(merged[i]) == name {
+				merged[i].Value = value
+				found = true
+				break
+			}
+		}
+		if !found && value != "" {
+			merged = append(merged, resxml.NewAttr(name, value))
+		}
+	}
+
+	return
+}
+
+// withAutoFormatted adds formatted="false" if value holds a literal '%'
+func withAutoFormatted(attrs []xml.Attr, value string) []xml.Attr {
+	if !model.HasLiteralPercent(value) {
+		return attrs
+	}
+	for _, attr := range attrs {
+		if resxml.AttrName(attr) == model.AttrFormatted {
+			return attrs
+		}
+	}
+	return append(attrs, resxml.NewAttr(model.AttrFormatted, "false"))
+}
+
+func attrsEqual(a, b []xml.Attr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if resxml.AttrName(a[i]) != resxml.AttrName(b[i]) || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+func usesTools(attrs []xml.Attr) bool {
+	for _, attr := range attrs {
+		if attr.Name.Space == "tools" {
+			return true
+		}
+	}
+	return false
+}
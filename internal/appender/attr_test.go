@@ -0,0 +1,131 @@
+package appender
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// formatAttrs writes attrs like name=value, joined by spaces
+func formatAttrs(attrs []xml.Attr) string {
+	s := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		s = append(s, resxml.AttrName(attr)+"="+attr.Value)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestMergeAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []xml.Attr
+		src  map[string]string
+		want string
+	}{
+		{name: "none"},
+		{
+			name: "kept",
+			old:  []xml.Attr{resxml.NewAttr("product", "tablet")},
+			want: "product=tablet",
+		},
+		{
+			name: "new ones sorted after old ones",
+			old:  []xml.Attr{resxml.NewAttr("product", "tablet")},
+			src:  map[string]string{"translatable": "false", "tools:ignore": "MissingTranslation", "formatted": "false"},
+			want: "product=tablet formatted=false tools:ignore=MissingTranslation translatable=false",
+		},
+		{
+			name: "replaced in place",
+			old:  []xml.Attr{resxml.NewAttr("translatable", "false"), resxml.NewAttr("product", "tablet")},
+			src:  map[string]string{"translatable": "true"},
+			want: "translatable=true product=tablet",
+		},
+		{
+			name: "empty source value not added",
+			src:  map[string]string{"translatable": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAttrs(mergeAttrs(tt.old, tt.src)); got != tt.want {
+				t.Errorf("mergeAttrs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAutoFormatted(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs []xml.Attr
+		value string
+		want  string
+	}{
+		{name: "no percent", value: "plain"},
+		{name: "literal percent", value: "100% sure", want: "formatted=false"},
+		{name: "specifier", value: "%d%% done"},
+		{
+			name:  "formatted already set",
+			attrs: []xml.Attr{resxml.NewAttr("formatted", "true")},
+			value: "100% sure",
+			want:  "formatted=true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAttrs(withAutoFormatted(tt.attrs, tt.value)); got != tt.want {
+				t.Errorf("withAutoFormatted(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceAttributes(t *testing.T) {
+	attributes := map[string]map[string]string{
+		"a": {"translatable": "false", "tools:ignore": "Typos", "formatted": "false"},
+	}
+	tests := []struct {
+		folder string
+		want   string
+	}{
+		{
+			folder: "values",
+			want:   "<resources xmlns:tools=\"http://schemas.android.com/tools\">\n    <string name=\"a\" formatted=\"false\" tools:ignore=\"Typos\" translatable=\"false\">A</string>\n</resources>\n",
+		},
+		{
+			folder: "values-night",
+			want:   "<resources xmlns:tools=\"http://schemas.android.com/tools\">\n    <string name=\"a\" formatted=\"false\" tools:ignore=\"Typos\" translatable=\"false\">A</string>\n</resources>\n",
+		},
+		{
+			folder: "values-fr",
+			want:   "<resources>\n    <string name=\"a\" formatted=\"false\">A</string>\n</resources>\n",
+		},
+		{
+			folder: "values-b+sr+Latn",
+			want:   "<resources>\n    <string name=\"a\" formatted=\"false\">A</string>\n</resources>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.folder, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), tt.folder, "strings.xml")
+			staging := make(map[string][]byte)
+			_, err := AppendToXML(map[string]string{"a": "A"}, output, nil, false,
+				WithAttributes(attributes), WithCreateMissing(), WithStaging(staging))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(staging[output])
+			got = got[strings.Index(got, "<resources"):]
+			if got != tt.want {
+				t.Errorf("AppendToXML() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if _, err = os.Stat(output); !os.IsNotExist(err) {
+				t.Errorf("staged file %v written", output)
+			}
+		})
+	}
+}
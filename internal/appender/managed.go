@@ -0,0 +1,172 @@
+package appender
+
+import (
+	"encoding/xml"
+	"sort"
+
+	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// applyManaged regenerates the managed block from data, resources defined
+// outside of the block are reported as duplicates and left alone
+func (a *xmlAppender) applyManaged(data map[string]string) error {
+	block, err := a.doc.ManagedBlock()
+	if err != nil {
+		return err
+	}
+
+	inside := make(map[string]*resxml.Element)
+	outside := make(map[string]*resxml.Element)
+	for _, e := range a.doc.Elements() {
+		id := resdir.ResourceID(e.Tag, e.Name)
+		if block != nil && block.Contains(e) {
+			inside[id] = e
+		} else {
+			outside[id] = e
+		}
+	}
+
+	strs, plurals, arrays := groupResources(data)
+
+	var lines []string
+	for _, key := range a.options.order.names(strs, plurals, arrays) {
+		srcAttrs := a.sourceAttrs(key)
+		if value, ok := strs[key]; ok && !a.duplicated(resxml.TagString, key, outside) {
+			e := inside[resdir.ResourceID(resxml.TagString, key)]
+			lines = append(lines, a.renderString(key, e, srcAttrs, value))
+			a.stats.wrote(resxml.TagString, key)
+		}
+		if items, ok := arrays[key]; ok && !a.duplicated(resxml.TagStringArray, key, outside) {
+			e := inside[resdir.ResourceID(resxml.TagStringArray, key)]
+			lines = append(lines, a.renderArray(key, e, srcAttrs, items))
+			a.stats.wrote(resxml.TagStringArray, key)
+		}
+		if items, ok := plurals[key]; ok && !a.duplicated(resxml.TagPlurals, key, outside) {
+			e := inside[resdir.ResourceID(resxml.TagPlurals, key)]
+			lines = append(lines, a.renderPlurals(key, e, srcAttrs, items))
+			a.stats.wrote(resxml.TagPlurals, key)
+		}
+	}
+
+	if block != nil && a.options.prune != nil {
+		// stale resources are dropped by the regeneration already
+		for _, s := range a.options.prune.stale(a.output, a.doc, block) {
+			switch a.options.prune.Policy {
+			case PruneComment:
+				lines = append(lines, commentOut(a.rawElement(s.element)))
+			case PruneObsolete:
+				a.obsolete = append(a.obsolete, &obsoleteResource{
+					tag:  s.Tag,
+					name: s.Key,
+					text: a.rawElement(s.element),
+				})
+			}
+		}
+	}
+
+	if a.toolsUsed {
+		a.doc.SetRootAttr("xmlns:tools", resxml.ToolsNamespace)
+	}
+
+	if block != nil {
+		a.doc.ReplaceBlock(block, lines)
+	} else if len(lines) > 0 {
+		a.doc.AppendBlock(lines, len(a.doc.Elements()) > 0 && !a.doc.LastLineBlank())
+	}
+
+	return nil
+}
+
+// duplicated reports whether a resource is defined outside of the block
+func (a *xmlAppender) duplicated(tag, key string, outside map[string]*resxml.Element) bool {
+	var locations []resdir.Location
+	if e, ok := outside[resdir.ResourceID(tag, key)]; ok {
+		locations = append(locations, resdir.Location{File: a.output, Line: e.Line})
+	}
+	locations = append(locations, a.options.index.Lookup(tag, key)...)
+	if len(locations) == 0 {
+		return false
+	}
+	a.stats.Skipped++
+	if a.options.duplicates != nil {
+		a.options.duplicates(key, locations)
+	}
+	return true
+}
+
+func (a *xmlAppender) renderString(key string, e *resxml.Element, srcAttrs map[string]string, value string) string {
+	var attrs []xml.Attr
+	if e == nil {
+		a.stats.added(key)
+		attrs = withAutoFormatted(mergeAttrs(nil, srcAttrs), value)
+	} else {
+		value = a.resolve(e.Line, key, e.Value(), value)
+		attrs = mergeAttrs(e.Attrs, srcAttrs)
+		if value != e.Value() {
+			attrs = withAutoFormatted(attrs, value)
+		}
+	}
+	a.toolsUsed = a.toolsUsed || usesTools(attrs)
+	return a.format.String(key, attrs, value)
+}
+
+func (a *xmlAppender) renderArray(key string, e *resxml.Element, srcAttrs map[string]string, items map[int]string) string {
+	indices := make([]int, 0, len(items))
+	for i := range items {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	var attrs []xml.Attr
+	values := make([]string, 0, len(indices))
+	if e != nil {
+		attrs = e.Attrs
+	}
+	for _, index := range indices {
+		value := items[index]
+		if e != nil && index < len(e.Items) {
+			value = a.resolve(e.Line, model.ArrayKey(key, index), e.Items[index].Value(), value)
+		} else {
+			a.stats.added(model.ArrayKey(key, index))
+		}
+		values = append(values, value)
+	}
+
+	attrs = mergeAttrs(attrs, srcAttrs)
+	a.toolsUsed = a.toolsUsed || usesTools(attrs)
+	return a.format.Array(resxml.TagStringArray, key, attrs, values)
+}
+
+func (a *xmlAppender) renderPlurals(key string, e *resxml.Element, srcAttrs map[string]string, items map[string]string) string {
+	quantities := make([]string, 0, len(items))
+	for q := range items {
+		quantities = append(quantities, q)
+	}
+	quantities = model.SortQuantities(quantities)
+
+	var attrs []xml.Attr
+	oldItems := make(map[string]*resxml.Item)
+	if e != nil {
+		attrs = e.Attrs
+		for _, item := range e.Items {
+			oldItems[item.Attr("quantity")] = item
+		}
+	}
+
+	values := make(map[string]string, len(items))
+	for _, quantity := range quantities {
+		value := items[quantity]
+		if item, ok := oldItems[quantity]; ok {
+			value = a.resolve(e.Line, model.PluralKey(key, quantity), item.Value(), value)
+		} else {
+			a.stats.added(model.PluralKey(key, quantity))
+		}
+		values[quantity] = value
+	}
+
+	attrs = mergeAttrs(attrs, srcAttrs)
+	a.toolsUsed = a.toolsUsed || usesTools(attrs)
+	return a.format.Plurals(key, attrs, quantities, values)
+}
@@ -0,0 +1,103 @@
+package appender
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+func TestApplyManaged(t *testing.T) {
+	overwrite := func(file string, pos int, key, old, newer string) string { return newer }
+	tests := []struct {
+		name       string
+		raw        string
+		data       map[string]string
+		index      resdir.Index
+		prune      *Prune
+		want       string
+		duplicates string
+		wantErr    string
+	}{
+		{
+			name: "new block",
+			raw:  "<resources>\n    <string name=\"hand\">Hand</string>\n</resources>\n",
+			data: map[string]string{"b": "B", "a": "A"},
+			want: "<resources>\n    <string name=\"hand\">Hand</string>\n\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <string name=\"b\">B</string>\n    <!-- i18n:end -->\n</resources>\n",
+		},
+		{
+			name: "block regenerated",
+			raw:  "<resources>\n    <!-- i18n:begin -->\n    <string name=\"b\" translatable=\"false\">B</string>\n    <string name=\"gone\">Gone</string>\n    <!-- i18n:end -->\n    <string name=\"hand\">Hand</string>\n</resources>\n",
+			data: map[string]string{"b": "B2", "a": "A", "c[one]": "# c", "c[other]": "# cs", "d[0]": "D"},
+			want: "<resources>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <string name=\"b\" translatable=\"false\">B2</string>\n" +
+				"    <plurals name=\"c\">\n        <item quantity=\"one\"># c</item>\n        <item quantity=\"other\"># cs</item>\n    </plurals>\n" +
+				"    <string-array name=\"d\">\n        <item>D</item>\n    </string-array>\n" +
+				"    <!-- i18n:end -->\n    <string name=\"hand\">Hand</string>\n</resources>\n",
+		},
+		{
+			name:       "defined outside of the block",
+			raw:        "<resources>\n    <string name=\"hand\">Hand</string>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <!-- i18n:end -->\n</resources>\n",
+			data:       map[string]string{"a": "A", "hand": "Other"},
+			want:       "<resources>\n    <string name=\"hand\">Hand</string>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <!-- i18n:end -->\n</resources>\n",
+			duplicates: "hand",
+		},
+		{
+			name:       "defined in another file",
+			raw:        "<resources>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <!-- i18n:end -->\n</resources>\n",
+			data:       map[string]string{"a": "A", "other": "Other"},
+			index:      resdir.Index{resdir.ResourceID(resxml.TagString, "other"): {{File: "values/other.xml", Line: 2}}},
+			want:       "<resources>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <!-- i18n:end -->\n</resources>\n",
+			duplicates: "other",
+		},
+		{
+			name: "stale commented out",
+			raw:  "<resources>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <string name=\"gone\">Gone</string>\n    <!-- i18n:end -->\n</resources>\n",
+			data: map[string]string{"a": "A"},
+			prune: &Prune{
+				Policy: PruneComment,
+				Keep:   map[string]bool{resdir.ResourceID(resxml.TagString, "a"): true},
+			},
+			want: "<resources>\n    <!-- i18n:begin -->\n    <string name=\"a\">A</string>\n    <!-- <string name=\"gone\">Gone</string> -->\n    <!-- i18n:end -->\n</resources>\n",
+		},
+		{
+			name:    "broken markers",
+			raw:     "<resources>\n    <!-- i18n:begin -->\n</resources>\n",
+			data:    map[string]string{"a": "A"},
+			wantErr: "cannot update managed block",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var duplicates []string
+			opts := []AppendOpt{
+				WithManagedBlock(),
+				WithIndex(tt.index),
+				WithDuplicateHandler(func(key string, locations []resdir.Location) {
+					duplicates = append(duplicates, key)
+				}),
+			}
+			if tt.prune != nil {
+				opts = append(opts, WithPrune(tt.prune))
+			}
+			got, err := appendStaged(t, tt.raw, tt.data, overwrite, opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("AppendToXML() err:%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("AppendToXML() =\n%q\nwant\n%q", got, tt.want)
+			}
+			sort.Strings(duplicates)
+			if strings.Join(duplicates, ",") != tt.duplicates {
+				t.Errorf("duplicates = %v, want %v", duplicates, tt.duplicates)
+			}
+		})
+	}
+}
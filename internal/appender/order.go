@@ -0,0 +1,234 @@
+package appender
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// Order tells where new resources are inserted
+type Order string
+
+const (
+	// OrderSorted appends new resources at the end, sorted by name
+	OrderSorted Order = "sorted"
+	// OrderSource appends new resources at the end, in source row order
+	OrderSource Order = "source"
+	// OrderNearest inserts each new resource next to the existing one sharing
+	// the longest name prefix, or in sorted position
+	OrderNearest Order = "nearest"
+	// OrderGroup inserts new resources under a comment header named after
+	// their source section or file
+	OrderGroup Order = "group"
+	// OrderResort sorts all resources of the changed files by name
+	OrderResort Order = "resort"
+)
+
+// ParseOrder validates an order name
+func ParseOrder(name string) (Order, error) {
+	switch o := Order(name); o {
+	case OrderSorted, OrderSource, OrderNearest, OrderGroup, OrderResort:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown order %q, expect %v, %v, %v, %v or %v", name, OrderSorted, OrderSource, OrderNearest, OrderGroup, OrderResort)
+}
+
+type keyOrder struct {
+	order  Order
+	source *model.KeyOrder
+}
+
+// WithOrder specifies where new resources are inserted, source gives the
+// row order and groups of resources for OrderSource and OrderGroup
+func WithOrder(order Order, source *model.KeyOrder) AppendOpt {
+	return func(op *appendOptions) {
+		op.order = &keyOrder{order: order, source: source}
+	}
+}
+
+// names returns all resource names of grouped data, in source order for
+// OrderSource and OrderGroup, sorted otherwise
+func (o *keyOrder) names(strs map[string]string, plurals map[string]map[string]string, arrays map[string]map[int]string) []string {
+	names := sortedNames(strs, plurals, arrays)
+	if o == nil || (o.order != OrderSource && o.order != OrderGroup) {
+		return names
+	}
+	// names missing in the sources go last
+	sort.SliceStable(names, func(i, j int) bool {
+		a, aok := o.source.Index(names[i])
+		b, bok := o.source.Index(names[j])
+		if aok != bok {
+			return aok
+		}
+		return a < b
+	})
+	return names
+}
+
+// insertAppended writes the new resources of the document in place
+func (a *xmlAppender) insertAppended() error {
+	order := OrderSorted
+	if a.options.order != nil {
+		order = a.options.order.order
+	}
+
+	switch order {
+	case OrderNearest:
+		a.insertNearest()
+	case OrderGroup:
+		a.insertGrouped()
+	case OrderResort:
+		if len(a.appended) > 0 || a.doc.Changed() {
+			return a.doc.Resort(a.appended)
+		}
+	default:
+		a.appendLines(a.appended)
+	}
+	return nil
+}
+
+func (a *xmlAppender) appendLines(entries []*resxml.Entry) {
+	texts := make([]string, 0, len(entries))
+	for _, v := range entries {
+		texts = append(texts, v.Text)
+	}
+	a.doc.AppendLines(texts, len(a.doc.Elements()) > 0 && !a.doc.LastLineBlank())
+}
+
+// existing returns the string resources of the document not removed by prune
+func (a *xmlAppender) existing() (elements []*resxml.Element) {
+	for _, e := range a.doc.Elements() {
+		if resdir.IsStringResource(e.Tag) && e.Name != "" && !a.removed[e] {
+			elements = append(elements, e)
+		}
+	}
+	return
+}
+
+// insertNearest inserts each new resource after the existing one sharing
+// the most leading name segments and sorting right before it, or before the
+// first of them if none sorts before it
+func (a *xmlAppender) insertNearest() {
+	elements := a.existing()
+	if len(elements) == 0 {
+		a.appendLines(a.appended)
+		return
+	}
+
+	entries := make([]*resxml.Entry, len(a.appended))
+	copy(entries, a.appended)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	after := make(map[*resxml.Element][]string)
+	before := make(map[*resxml.Element][]string)
+	for _, entry := range entries {
+		best := 0
+		for _, e := range elements {
+			if n := sharedSegments(e.Name, entry.Name); n > best {
+				best = n
+			}
+		}
+		var anchor, first *resxml.Element
+		for _, e := range elements {
+			if sharedSegments(e.Name, entry.Name) != best {
+				continue
+			}
+			if e.Name < entry.Name && (anchor == nil || e.Name >= anchor.Name) {
+				anchor = e
+			}
+			if first == nil || e.Name < first.Name {
+				first = e
+			}
+		}
+		if anchor != nil {
+			after[anchor] = append(after[anchor], entry.Text)
+		} else {
+			before[first] = append(before[first], entry.Text)
+		}
+	}
+
+	for _, e := range elements {
+		a.doc.InsertLinesBefore(e, before[e])
+		a.doc.InsertLinesAfter(e, after[e])
+	}
+}
+
+// sharedSegments counts the leading name segments separated by _ or . two
+// names have in common
+func sharedSegments(a, b string) int {
+	split := func(r rune) bool {
+		return r == '_' || r == '.'
+	}
+	as, bs := strings.FieldsFunc(a, split), strings.FieldsFunc(b, split)
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+	return n
+}
+
+// insertGrouped inserts new resources after the last resource under the
+// comment header of their group, a header is added at the end if missing
+func (a *xmlAppender) insertGrouped() {
+	var groups []string
+	grouped := make(map[string][]*resxml.Entry)
+	var ungrouped []*resxml.Entry
+	for _, entry := range a.appended {
+		group := a.options.order.source.Group(entry.Name)
+		if group == "" {
+			ungrouped = append(ungrouped, entry)
+			continue
+		}
+		if _, ok := grouped[group]; !ok {
+			groups = append(groups, group)
+		}
+		grouped[group] = append(grouped[group], entry)
+	}
+
+	a.appendLines(ungrouped)
+
+	elements := a.existing()
+	comments := a.doc.Comments()
+	separate := len(a.doc.Elements()) > 0 && !a.doc.LastLineBlank()
+	for _, group := range groups {
+		header := commentText(group)
+		texts := make([]string, 0, len(grouped[group])+1)
+		for _, v := range grouped[group] {
+			texts = append(texts, v.Text)
+		}
+
+		var c, next *resxml.Comment
+		for i, v := range comments {
+			if strings.EqualFold(v.Text, header) {
+				c = v
+				if i+1 < len(comments) {
+					next = comments[i+1]
+				}
+				break
+			}
+		}
+		if c == nil {
+			a.doc.AppendLines(append([]string{"<!-- " + header + " -->"}, texts...), separate || len(ungrouped) > 0)
+			separate = true
+			continue
+		}
+
+		var last *resxml.Element
+		for _, e := range elements {
+			if e.Start > c.End && (next == nil || e.End < next.Start) {
+				last = e
+			}
+		}
+		if last != nil {
+			a.doc.InsertLinesAfter(last, texts)
+		} else {
+			a.doc.InsertLinesAfterComment(c, texts)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package appender
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// PrunePolicy tells what to do with resources no longer in any source
+type PrunePolicy string
+
+const (
+	PruneDelete   PrunePolicy = "delete"
+	PruneComment  PrunePolicy = "comment"
+	PruneObsolete PrunePolicy = "obsolete"
+)
+
+// ParsePrunePolicy validates a prune policy name
+func ParsePrunePolicy(name string) (PrunePolicy, error) {
+	switch p := PrunePolicy(name); p {
+	case PruneDelete, PruneComment, PruneObsolete:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown prune policy %q, expect %v, %v or %v", name, PruneDelete, PruneComment, PruneObsolete)
+}
+
+// Prune describes which resources are pruned and how
+type Prune struct {
+	Policy PrunePolicy
+	// Keep holds the resource ids still defined by the sources
+	Keep map[string]bool
+	// Tracked reports whether a resource is managed by i18n, not used in
+	// managed block mode where the block content is tracked
+	Tracked func(tag, name string) bool
+	// ObsoleteFile is the name of the file obsolete resources are moved to,
+	// see ObsoletePath
+	ObsoleteFile string
+}
+
+// ObsoleteDir holds the obsolete files, next to the res directory so they are
+// not compiled
+const ObsoleteDir = "i18n-obsolete"
+
+// ObsoletePath returns the obsolete file of a resource file, like
+// main/i18n-obsolete/values-fr/strings_obsolete.xml for
+// main/res/values-fr/strings.xml. A file in the values folder would still be
+// compiled by aapt, tools:ignore only silences lint
+func ObsoletePath(output, file string) string {
+	folder := filepath.Dir(output)
+	res := filepath.Dir(folder)
+	return filepath.Join(filepath.Dir(res), ObsoleteDir, filepath.Base(folder), file)
+}
+
+// Stale is a resource no longer in any source
+type Stale struct {
+	Key      string          `json:"key"`
+	Tag      string          `json:"tag"`
+	Location resdir.Location `json:"location"`
+	element  *resxml.Element
+}
+
+// WithPrune prunes resources no longer in any source
+func WithPrune(prune *Prune) AppendOpt {
+	return func(op *appendOptions) {
+		op.prune = prune
+	}
+}
+
+// ListStale returns the resources output would prune, with the same options
+// as AppendToXML. The other files of its folder are hand-written and never
+// pruned
+func ListStale(output string, opts ...AppendOpt) (stale []*Stale, err error) {
+	options := &appendOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.prune == nil {
+		return
+	}
+
+	var raw []byte
+	raw, err = ioutil.ReadFile(output)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return
+	}
+	var doc *resxml.Document
+	doc, err = resxml.Parse(raw)
+	if err != nil {
+		err = fmt.Errorf("cannot parse %v, err:%v", output, err)
+		return
+	}
+	var block *resxml.Block
+	if options.managed {
+		block, err = doc.ManagedBlock()
+		if err != nil || block == nil {
+			return
+		}
+	}
+	return options.prune.stale(output, doc, block), nil
+}
+
+// stale returns the tracked resources of a document not in Keep, only the
+// ones inside block if it is not nil
+func (p *Prune) stale(file string, doc *resxml.Document, block *resxml.Block) (stale []*Stale) {
+	for _, e := range doc.Elements() {
+		if !resdir.IsStringResource(e.Tag) || e.Name == "" {
+			continue
+		}
+		if p.Keep[resdir.ResourceID(e.Tag, e.Name)] {
+			continue
+		}
+		if block != nil {
+			if !block.Contains(e) {
+				continue
+			}
+		} else if p.Tracked == nil || !p.Tracked(e.Tag, e.Name) {
+			continue
+		}
+		stale = append(stale, &Stale{
+			Key:      e.Name,
+			Tag:      e.Tag,
+			Location: resdir.Location{File: file, Line: e.Line},
+			element:  e,
+		})
+	}
+	return
+}
+
+// prune applies the policy to the stale resources of the document, outside
+// of a managed block
+func (a *xmlAppender) prune(stale []*Stale) {
+	for _, s := range stale {
+		switch a.options.prune.Policy {
+		case PruneDelete:
+			a.doc.Remove(s.element)
+			a.removed[s.element] = true
+		case PruneComment:
+			a.doc.Replace(s.element, commentOut(a.rawElement(s.element)))
+		case PruneObsolete:
+			a.doc.Remove(s.element)
+			a.removed[s.element] = true
+			a.obsolete = append(a.obsolete, &obsoleteResource{
+				tag:  s.Tag,
+				name: s.Key,
+				text: a.rawElement(s.element),
+			})
+		}
+	}
+}
+
+func (a *xmlAppender) rawElement(e *resxml.Element) string {
+	return string(a.doc.Raw()[e.Start:e.End])
+}
+
+// commentOut wraps an element in a comment
+func commentOut(text string) string {
+	return "<!-- " + commentText(text) + " -->"
+}
+
+// commentText makes text safe in a comment, "--" is not allowed in comments
+// and "---" still holds one after a single pass
+func commentText(text string) string {
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "- -")
+	}
+	return text
+}
+
+type obsoleteResource struct {
+	tag  string
+	name string
+	text string
+}
+
+// writeObsolete moves the obsolete resources of all appenders to the
+// obsolete file of the folder, resources back in the sources are removed from
+// it
+func writeObsolete(output string, appenders []*xmlAppender, options *appendOptions, dry bool) (err error) {
+	var obsolete []*obsoleteResource
+	for _, v := range appenders {
+		obsolete = append(obsolete, v.obsolete...)
+	}
+	if dry && options.staging == nil {
+		return
+	}
+	sort.SliceStable(obsolete, func(i, j int) bool {
+		return obsolete[i].name < obsolete[j].name
+	})
+
+	path := ObsoletePath(output, options.prune.ObsoleteFile)
+	var raw []byte
+	raw, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		if len(obsolete) == 0 {
+			return nil
+		}
+		err = nil
+	} else if err != nil {
+		return
+	}
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		raw = []byte(resxml.Skeleton)
+	}
+
+	var doc *resxml.Document
+	doc, err = resxml.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("cannot parse %v, err:%v", path, err)
+	}
+
+	for _, e := range doc.Elements() {
+		if options.prune.Keep[resdir.ResourceID(e.Tag, e.Name)] {
+			doc.Remove(e)
+		}
+	}
+
+	// newer definitions replace older ones
+	var appended []string
+	for _, v := range obsolete {
+		if old := doc.Lookup(v.tag, v.name); old != nil {
+			doc.Replace(old, v.text)
+		} else {
+			appended = append(appended, v.text)
+		}
+	}
+	doc.AppendLines(appended, len(doc.Elements()) > 0 && !doc.LastLineBlank())
+	if !doc.Changed() {
+		return nil
+	}
+
+	content, err := doc.Bytes()
+	if err != nil {
+		return fmt.Errorf("cannot edit %v, err:%v", path, err)
+	}
+	return options.write(path, content)
+}
+
+// State records the resources written by i18n, to track them for pruning
+type State struct {
+	Resources []string `json:"resources"`
+	set       map[string]bool
+}
+
+// LoadState reads a state file, a missing file gives an empty state
+func LoadState(path string) (state *State, err error) {
+	state = &State{}
+	var raw []byte
+	raw, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		err = nil
+	} else if err == nil {
+		err = json.Unmarshal(raw, state)
+		if err != nil {
+			err = fmt.Errorf("cannot parse state file %v, err:%v", path, err)
+		}
+	}
+	state.set = make(map[string]bool)
+	for _, id := range state.Resources {
+		state.set[id] = true
+	}
+	return
+}
+
+// Tracked reports whether the resource was written by i18n
+func (s *State) Tracked(tag, name string) bool {
+	return s.set[resdir.ResourceID(tag, name)]
+}
+
+// Bytes returns the content of the state with resources as the tracked
+// resource ids
+func (s *State) Bytes(resources map[string]bool) ([]byte, error) {
+	s.Resources = make([]string, 0, len(resources))
+	for id := range resources {
+		s.Resources = append(s.Resources, id)
+	}
+	sort.Strings(s.Resources)
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, '\n'), nil
+}
@@ -0,0 +1,151 @@
+package appender
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+func TestCommentOut(t *testing.T) {
+	for _, text := range []string{
+		`<string name="a">--</string>`,
+		`<string name="a">---</string>`,
+		`<string name="a">a----b</string>`,
+		`<string name="a">-</string>`,
+	} {
+		raw := "<resources>\n    " + commentOut(text) + "\n</resources>\n"
+		doc, err := resxml.Parse([]byte(raw))
+		if err != nil {
+			t.Errorf("commentOut(%q) = %q, err:%v", text, raw, err)
+			continue
+		}
+		if len(doc.Comments()) != 1 || len(doc.Elements()) != 0 {
+			t.Errorf("commentOut(%q) = %q, not a single comment", text, raw)
+		}
+	}
+}
+
+func TestPruneObsolete(t *testing.T) {
+	src := t.TempDir()
+	output := filepath.Join(src, "res", "values-fr", "strings.xml")
+	obsolete := filepath.Join(src, ObsoleteDir, "values-fr", "strings_obsolete.xml")
+	err := os.MkdirAll(filepath.Dir(output), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(output, []byte("<resources>\n    <string name=\"a\">A</string>\n    <string name=\"b\">B</string>\n</resources>\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prune := func(keep ...string) *Prune {
+		p := &Prune{
+			Policy:       PruneObsolete,
+			Keep:         make(map[string]bool),
+			Tracked:      func(tag, name string) bool { return true },
+			ObsoleteFile: "strings_obsolete.xml",
+		}
+		for _, name := range keep {
+			p.Keep[resdir.ResourceID(resxml.TagString, name)] = true
+		}
+		return p
+	}
+	apply := func(data map[string]string, p *Prune) {
+		staging := make(map[string][]byte)
+		_, err := AppendToXML(data, output, nil, false, WithPrune(p), WithStaging(staging))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path, content := range staging {
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+			if err == nil {
+				err = ioutil.WriteFile(path, content, 0644)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	lookup := func(path, name string) bool {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := resxml.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc.Lookup(resxml.TagString, name) != nil
+	}
+
+	apply(map[string]string{"a": "A"}, prune("a"))
+	if lookup(output, "b") {
+		t.Errorf("b kept in %v", output)
+	}
+	if !lookup(obsolete, "b") {
+		t.Errorf("b not moved to %v", obsolete)
+	}
+
+	apply(map[string]string{"a": "A", "b": "B2"}, prune("a", "b"))
+	if !lookup(output, "b") {
+		t.Errorf("b back in sources not added to %v", output)
+	}
+	if lookup(obsolete, "b") {
+		t.Errorf("b back in sources kept in %v", obsolete)
+	}
+}
+
+func TestPruneTargetOnly(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "res", "values")
+	output := filepath.Join(folder, "strings.xml")
+	sibling := filepath.Join(folder, "feature.xml")
+	err := os.MkdirAll(folder, 0755)
+	if err == nil {
+		err = ioutil.WriteFile(output, []byte("<resources>\n    <string name=\"feature_a\">A</string>\n    <string name=\"feature_old\">Old</string>\n</resources>\n"), 0644)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(sibling, []byte("<resources>\n    <string name=\"feature_b\">B</string>\n    <string name=\"feature_hand\">Hand</string>\n</resources>\n"), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Prune{
+		Policy: PruneDelete,
+		Keep: map[string]bool{
+			resdir.ResourceID(resxml.TagString, "feature_a"): true,
+			resdir.ResourceID(resxml.TagString, "feature_b"): true,
+			resdir.ResourceID(resxml.TagString, "feature_c"): true,
+		},
+		Tracked: func(tag, name string) bool { return strings.HasPrefix(name, "feature_") },
+	}
+
+	stale, err := ListStale(output, WithPrune(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0].Key != "feature_old" || stale[0].Location.File != output {
+		t.Errorf("ListStale() = %v, want feature_old of %v only", stale, output)
+	}
+
+	staging := make(map[string][]byte)
+	data := map[string]string{"feature_a": "A", "feature_b": "B2", "feature_c": "C"}
+	stats, err := AppendToXML(data, output, func(file string, pos int, key, old, newer string) string { return newer }, false, WithPrune(p), WithStaging(staging))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<resources>\n    <string name=\"feature_a\">A</string>\n\n    <string name=\"feature_c\">C</string>\n</resources>\n"; string(staging[output]) != want {
+		t.Errorf("%v =\n%q\nwant\n%q", output, staging[output], want)
+	}
+	if want := "<resources>\n    <string name=\"feature_b\">B2</string>\n    <string name=\"feature_hand\">Hand</string>\n</resources>\n"; string(staging[sibling]) != want {
+		t.Errorf("%v =\n%q\nwant\n%q", sibling, staging[sibling], want)
+	}
+	sort.Strings(stats.Resources)
+	if got := strings.Join(stats.Resources, ","); got != "string/feature_a,string/feature_c" {
+		t.Errorf("Resources = %v, want the ones written to %v", got, output)
+	}
+}
@@ -0,0 +1,49 @@
+package appender
+
+import "github.com/master-g/i18n/internal/resdir"
+
+// Stats counts the keys of an append, plural quantities and array items
+// count as keys of their own
+type Stats struct {
+	// Appended keys did not exist before
+	Appended int
+	// Changed keys got a new value
+	Changed int
+	// Unchanged keys already had the value of the source
+	Unchanged int
+	// Skipped keys kept their value on a collision, or were defined outside
+	// of the managed block
+	Skipped int
+	// Collisions are keys with a value different from the source
+	Collisions int
+
+	// AddedKeys and CollidedKeys name the keys appended and collided
+	AddedKeys    []string
+	CollidedKeys []string
+	// Resources are the ids of the resources written to the target file,
+	// plain, plural and array resources alike
+	Resources []string
+}
+
+// added counts keys appended
+func (s *Stats) added(keys ...string) {
+	s.Appended += len(keys)
+	s.AddedKeys = append(s.AddedKeys, keys...)
+}
+
+// wrote records a resource written to the file
+func (s *Stats) wrote(tag, name string) {
+	s.Resources = append(s.Resources, resdir.ResourceID(tag, name))
+}
+
+// Add adds the counts of other
+func (s *Stats) Add(other *Stats) {
+	s.Appended += other.Appended
+	s.Changed += other.Changed
+	s.Unchanged += other.Unchanged
+	s.Skipped += other.Skipped
+	s.Collisions += other.Collisions
+	s.AddedKeys = append(s.AddedKeys, other.AddedKeys...)
+	s.CollidedKeys = append(s.CollidedKeys, other.CollidedKeys...)
+	s.Resources = append(s.Resources, other.Resources...)
+}
@@ -4,164 +4,539 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/master-g/i18n/internal/escape"
 	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
 	"github.com/master-g/i18n/pkg/wkfs"
 )
 
-type xmlLine struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-	Pos   int    `json:"pos"`
-	Lines int    `json:"lines"`
+type CollisionResolver func(file string, pos int, key, old, newer string) string
+
+// DuplicateHandler is called for a resource defined more than once, or
+// defined outside of the managed block it would be written to
+type DuplicateHandler func(key string, locations []resdir.Location)
+
+type AppendOpt func(options *appendOptions)
+
+type appendOptions struct {
+	attributes    map[string]map[string]string
+	createMissing bool
+	managed       bool
+	index         resdir.Index
+	duplicates    DuplicateHandler
+	prune         *Prune
+	order         *keyOrder
+	staging       map[string][]byte
 }
 
-type CollisionResolver func(file string, pos int, key, old, newer string) string
+// WithStaging collects the content of changed files into staging instead of
+// writing them, to commit them all at once. Files are staged on dry runs too
+func WithStaging(staging map[string][]byte) AppendOpt {
+	return func(op *appendOptions) {
+		op.staging = staging
+	}
+}
 
-func AppendToXML(data map[string]string, output string, resolver CollisionResolver, dry bool) (keyCollisions, keyAppended int, err error) {
-	var lines []string
-	lines, err = wkfs.ReadAllLines(output)
+// write writes a file, or stages it
+func (op *appendOptions) write(path string, content []byte) error {
+	if op.staging != nil {
+		op.staging[path] = content
+		return nil
+	}
+	err := wkfs.EnsureDir(filepath.Dir(path))
 	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// WithAttributes specifies attributes to set on resources, by resource name
+func WithAttributes(attributes map[string]map[string]string) AppendOpt {
+	return func(op *appendOptions) {
+		op.attributes = attributes
+	}
+}
+
+// WithCreateMissing creates the output file and its folder if they do not exist
+func WithCreateMissing() AppendOpt {
+	return func(op *appendOptions) {
+		op.createMissing = true
+	}
+}
+
+// WithManagedBlock only writes the region between <!-- i18n:begin --> and
+// <!-- i18n:end -->, the block is regenerated from data and added at the end
+// of the file if missing
+func WithManagedBlock() AppendOpt {
+	return func(op *appendOptions) {
+		op.managed = true
+	}
+}
+
+// WithIndex specifies the resources defined by other files of the folder,
+// used in managed block mode
+func WithIndex(idx resdir.Index) AppendOpt {
+	return func(op *appendOptions) {
+		op.index = idx
+	}
+}
+
+// WithDuplicateHandler specifies the handler of keys defined elsewhere
+func WithDuplicateHandler(handler DuplicateHandler) AppendOpt {
+	return func(op *appendOptions) {
+		op.duplicates = handler
+	}
+}
+
+// AppendToXML appends data to a string xml file, keys like name[quantity]
+// are written as <plurals> items, keys like name[0] as <string-array> items.
+// Only the elements being added or changed are rewritten, attributes of
+// replaced resources are kept, formatted="false" is added to written strings
+// holding a literal '%'. Resources already defined by another xml file of the
+// same folder are updated in that file
+func AppendToXML(data map[string]string, output string, resolver CollisionResolver, dry bool, opts ...AppendOpt) (stats *Stats, err error) {
+	options := &appendOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	err = checkArrays(data)
+	if err != nil {
+		err = fmt.Errorf("cannot append to %v, err:%v", output, err)
 		return
 	}
 
-	newFileLines := make([]string, 0, len(lines))
+	var raw []byte
+	raw, err = ioutil.ReadFile(output)
+	if os.IsNotExist(err) && options.createMissing {
+		err = nil
+	} else if err != nil {
+		return
+	}
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		// empty or missing file
+		raw = []byte(resxml.Skeleton)
+	}
 
-	multiLineStart := 0
-	multiLineEnd := 0
-	multiLine := false
-	buf := &strings.Builder{}
-	oldSet := make(map[string]*xmlLine)
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	var doc *resxml.Document
+	doc, err = resxml.Parse(raw)
+	if err != nil {
+		err = fmt.Errorf("cannot parse %v, err:%v", output, err)
+		return
+	}
 
-		found := false
+	a := newXMLAppender(doc, output, resolver, options)
+	appenders := []*xmlAppender{a}
+	if options.managed {
+		err = a.applyManaged(data)
+		if err != nil {
+			err = fmt.Errorf("cannot update managed block of %v, err:%v", output, err)
+			return
+		}
+	} else {
+		exclude := []string{output}
+		if options.prune != nil {
+			// obsolete files left in the folder by older versions are not updated
+			exclude = append(exclude, filepath.Join(filepath.Dir(output), options.prune.ObsoleteFile))
+		}
+		var siblings []*resdir.File
+		siblings, err = resdir.LoadFolder(filepath.Dir(output), exclude...)
+		if err != nil {
+			return
+		}
+		for _, f := range siblings {
+			appenders = append(appenders, newXMLAppender(f.Doc, f.Path, resolver, options))
+		}
+		reportDuplicates(appenders, options.duplicates)
 
-		if multiLine {
-			buf.WriteString(trimmed)
-			if strings.HasPrefix(trimmed, "<string name=") {
-				err = fmt.Errorf("invalid xml, line: %d", i)
-				return
-			} else if strings.HasSuffix(trimmed, "</string>") {
-				trimmed = buf.String()
-				buf.Reset()
-				found = true
-				multiLineEnd = i
+		routed := routeResources(data, appenders)
+		for _, v := range appenders {
+			if len(routed[v]) > 0 {
+				v.apply(routed[v])
 			}
-		} else if strings.HasPrefix(trimmed, "<string name=") {
-			if strings.HasSuffix(trimmed, "</string>") {
-				found = true
-			} else {
-				multiLineStart = i
-				multiLine = true
-				buf.WriteString(trimmed)
+			// other files of the folder are hand-written, only the file
+			// i18n writes is pruned
+			if options.prune != nil && v == a {
+				v.prune(options.prune.stale(v.output, v.doc, nil))
 			}
-		}
-
-		if found {
-			m := &model.StringXMLItem{}
-			err = xml.Unmarshal([]byte(trimmed), m)
+			err = v.insertAppended()
 			if err != nil {
+				err = fmt.Errorf("cannot insert keys into %v, err:%v", v.output, err)
 				return
 			}
+		}
+	}
+
+	stats = &Stats{}
+	for _, v := range appenders {
+		stats.Add(&v.stats)
+		if !v.doc.Changed() {
+			continue
+		}
+		var content []byte
+		content, err = v.doc.Bytes()
+		if err != nil {
+			err = fmt.Errorf("cannot edit %v, err:%v", v.output, err)
+			return
+		}
+		if dry && options.staging == nil {
+			continue
+		}
+		err = options.write(v.output, content)
+		if err != nil {
+			return
+		}
+	}
+
+	// resources updated in the other files of the folder stay hand-written
+	stats.Resources = a.stats.Resources
+
+	if options.prune != nil && options.prune.Policy == PruneObsolete {
+		err = writeObsolete(output, appenders, options, dry)
+	}
+
+	return
+}
+
+// reportDuplicates reports string resources defined more than once within
+// the files of a folder
+func reportDuplicates(appenders []*xmlAppender, handler DuplicateHandler) {
+	if handler == nil {
+		return
+	}
+	idx := make(resdir.Index)
+	var ids []string
+	for _, v := range appenders {
+		for _, e := range v.doc.Elements() {
+			if !resdir.IsStringResource(e.Tag) || e.Name == "" {
+				continue
+			}
+			id := resdir.ResourceID(e.Tag, e.Name)
+			if idx[id] == nil {
+				ids = append(ids, id)
+			}
+			idx[id] = append(idx[id], resdir.Location{File: v.output, Line: e.Line})
+		}
+	}
+	for _, id := range ids {
+		if len(idx[id]) > 1 {
+			handler(id[strings.IndexRune(id, '/')+1:], idx[id])
+		}
+	}
+}
 
-			pos := i
-			numOfLines := 1
-			if multiLine {
-				pos = multiLineStart
-				numOfLines = multiLineEnd - multiLineStart + 1
+// routeResources splits data by the file each resource already lives in,
+// new resources go to the first appender
+func routeResources(data map[string]string, appenders []*xmlAppender) map[*xmlAppender]map[string]string {
+	owners := make(map[string]*xmlAppender)
+	for _, v := range appenders {
+		for _, e := range v.doc.Elements() {
+			id := resdir.ResourceID(e.Tag, e.Name)
+			if _, ok := owners[id]; !ok {
+				owners[id] = v
 			}
+		}
+	}
+
+	routed := make(map[*xmlAppender]map[string]string)
+	for key, value := range data {
+		name, tag := resdir.KeyResource(key)
+		owner, ok := owners[resdir.ResourceID(tag, name)]
+		if !ok {
+			owner = appenders[0]
+		}
+		if routed[owner] == nil {
+			routed[owner] = make(map[string]string)
+		}
+		routed[owner][key] = value
+	}
+	return routed
+}
 
-			oldSet[m.Name] = &xmlLine{
-				Key:   m.Name,
-				Value: m.Value,
-				Pos:   pos,
-				Lines: numOfLines,
+func newXMLAppender(doc *resxml.Document, output string, resolver CollisionResolver, options *appendOptions) *xmlAppender {
+	localized := false
+	if f, err := resdir.ParseFolder(filepath.Base(filepath.Dir(output))); err == nil {
+		localized = f.HasLocale()
+	}
+	return &xmlAppender{
+		doc:       doc,
+		format:    doc.Formatter(),
+		output:    output,
+		resolver:  resolver,
+		options:   options,
+		localized: localized,
+		removed:   make(map[*resxml.Element]bool),
+	}
+}
+
+type xmlAppender struct {
+	doc      *resxml.Document
+	format   *resxml.Formatter
+	output   string
+	resolver CollisionResolver
+	options  *appendOptions
+	// localized files are in a folder with a locale qualifier
+	localized bool
+
+	stats     Stats
+	toolsUsed bool
+	appended  []*resxml.Entry
+	obsolete  []*obsoleteResource
+	removed   map[*resxml.Element]bool
+}
+
+func (a *xmlAppender) apply(data map[string]string) {
+	oldStrings := make(map[string]*resxml.Element)
+	oldPlurals := make(map[string]*resxml.Element)
+	oldArrays := make(map[string]*resxml.Element)
+	for _, e := range a.doc.Elements() {
+		// the first definition of duplicates is updated
+		switch e.Tag {
+		case resxml.TagString:
+			if _, ok := oldStrings[e.Name]; !ok {
+				oldStrings[e.Name] = e
+			}
+		case resxml.TagPlurals:
+			if _, ok := oldPlurals[e.Name]; !ok {
+				oldPlurals[e.Name] = e
+			}
+		case resxml.TagStringArray:
+			if _, ok := oldArrays[e.Name]; !ok {
+				oldArrays[e.Name] = e
 			}
+		}
+	}
+
+	strs, plurals, arrays := groupResources(data)
 
-			multiLine = false
-			multiLineStart = 0
-			multiLineEnd = 0
-		} else if strings.HasPrefix(trimmed, "</resources>") {
-			break
+	for _, key := range a.options.order.names(strs, plurals, arrays) {
+		srcAttrs := a.sourceAttrs(key)
+		if value, ok := strs[key]; ok {
+			a.applyString(key, oldStrings[key], srcAttrs, value)
+			a.stats.wrote(resxml.TagString, key)
+		}
+		if items, ok := arrays[key]; ok {
+			a.applyArray(key, oldArrays[key], srcAttrs, items)
+			a.stats.wrote(resxml.TagStringArray, key)
+		}
+		if items, ok := plurals[key]; ok {
+			a.applyPlurals(key, oldPlurals[key], srcAttrs, items)
+			a.stats.wrote(resxml.TagPlurals, key)
 		}
-		newFileLines = append(newFileLines, line)
 	}
 
-	appendFlag := false
-	lastLine := strings.TrimSpace(newFileLines[len(newFileLines)-1])
-	if lastLine == "" {
-		appendFlag = true
+	// tools:* attributes need the namespace declared on <resources>
+	if a.toolsUsed {
+		a.doc.SetRootAttr("xmlns:tools", resxml.ToolsNamespace)
+	}
+}
+
+// resolve returns the value to write when newer differs from old, values
+// reading the same, like \' and "'", do not collide
+func (a *xmlAppender) resolve(line int, key, old, newer string) string {
+	if newer == strings.TrimSpace(old) || escape.Same(escape.Android, old, newer) {
+		a.stats.Unchanged++
+		return old
+	}
+	a.stats.Collisions++
+	a.stats.CollidedKeys = append(a.stats.CollidedKeys, key)
+	value := old
+	if a.resolver != nil {
+		value = a.resolver(a.output, line, key, old, newer)
+	}
+	if value == old {
+		a.stats.Skipped++
+	} else {
+		a.stats.Changed++
+	}
+	return value
+}
+
+func (a *xmlAppender) applyString(key string, e *resxml.Element, srcAttrs map[string]string, value string) {
+	if e == nil {
+		attrs := withAutoFormatted(mergeAttrs(nil, srcAttrs), value)
+		a.toolsUsed = a.toolsUsed || usesTools(attrs)
+		a.stats.added(key)
+		a.appended = append(a.appended, &resxml.Entry{Name: key, Text: a.format.String(key, attrs, value)})
+		return
 	}
 
-	sortedKeys := make([]string, 0, len(data))
-	for key := range data {
-		sortedKeys = append(sortedKeys, key)
+	value = a.resolve(e.Line, key, e.Value(), value)
+	changed := value != e.Value()
+
+	attrs := mergeAttrs(e.Attrs, srcAttrs)
+	if changed {
+		attrs = withAutoFormatted(attrs, value)
+	}
+	if !attrsEqual(attrs, e.Attrs) {
+		a.toolsUsed = a.toolsUsed || usesTools(attrs)
+		a.doc.ReplaceStartTag(e, a.format.StartTag(e.Tag, key, attrs))
 	}
-	sort.Strings(sortedKeys)
+	if changed {
+		a.doc.ReplaceInner(e, value)
+	}
+}
 
-	for _, key := range sortedKeys {
-		value := data[key]
-		newEntry := &model.StringXMLItem{
-			Name:  key,
-			Value: value,
+// applyArray replaces the items of an array with the source items, items
+// the source no longer has are removed
+func (a *xmlAppender) applyArray(key string, e *resxml.Element, srcAttrs map[string]string, items map[int]string) {
+	if e == nil {
+		values := make([]string, len(items))
+		for index, value := range items {
+			values[index] = value
+			a.stats.added(model.ArrayKey(key, index))
 		}
-		var newBytes []byte
-		newBytes, err = xml.MarshalIndent(newEntry, "    ", "")
-		if err != nil {
-			return
+		attrs := mergeAttrs(nil, srcAttrs)
+		a.toolsUsed = a.toolsUsed || usesTools(attrs)
+		a.appended = append(a.appended, &resxml.Entry{Name: key, Text: a.format.Array(resxml.TagStringArray, key, attrs, values)})
+		return
+	}
+
+	for index := 0; index < len(items); index++ {
+		value := items[index]
+		if index >= len(e.Items) {
+			// extend
+			a.stats.added(model.ArrayKey(key, index))
+			a.doc.AppendItem(e, a.format.Item(nil, value))
+			continue
+		}
+		item := e.Items[index]
+		value = a.resolve(e.Line, model.ArrayKey(key, index), item.Value(), value)
+		if value != item.Value() {
+			a.doc.ReplaceItem(item, value)
+		}
+	}
+	for index := len(items); index < len(e.Items); index++ {
+		a.doc.RemoveItem(e.Items[index])
+	}
+
+	attrs := mergeAttrs(e.Attrs, srcAttrs)
+	if !attrsEqual(attrs, e.Attrs) {
+		a.toolsUsed = a.toolsUsed || usesTools(attrs)
+		a.doc.ReplaceStartTag(e, a.format.StartTag(e.Tag, key, attrs))
+	}
+}
+
+func (a *xmlAppender) applyPlurals(key string, e *resxml.Element, srcAttrs map[string]string, items map[string]string) {
+	quantities := make([]string, 0, len(items))
+	for q := range items {
+		quantities = append(quantities, q)
+	}
+	quantities = model.SortQuantities(quantities)
+
+	if e == nil {
+		for _, quantity := range quantities {
+			a.stats.added(model.PluralKey(key, quantity))
 		}
-		newLine := string(newBytes)
-
-		if oldEntry, ok := oldSet[key]; ok {
-			if value != strings.TrimSpace(oldEntry.Value) {
-				keyCollisions++
-				if resolver != nil {
-					value = resolver(output, oldEntry.Pos, key, oldEntry.Value, value)
-				} else {
-					value = oldEntry.Value
-				}
-				m := &model.StringXMLItem{
-					Name:  key,
-					Value: value,
-				}
-
-				// replace
-				var replaceData []byte
-				replaceData, err = xml.MarshalIndent(m, "    ", "")
-				if err != nil {
-					return
-				}
-				newFileLines[oldEntry.Pos] = string(replaceData)
-				if oldEntry.Lines > 1 {
-					newFileLines = append(newFileLines[:oldEntry.Pos+1], newFileLines[oldEntry.Pos+oldEntry.Lines:]...)
-				}
+		attrs := mergeAttrs(nil, srcAttrs)
+		a.toolsUsed = a.toolsUsed || usesTools(attrs)
+		a.appended = append(a.appended, &resxml.Entry{Name: key, Text: a.format.Plurals(key, attrs, quantities, items)})
+		return
+	}
+
+	oldItems := make(map[string]*resxml.Item)
+	for _, item := range e.Items {
+		oldItems[item.Attr("quantity")] = item
+	}
+
+	for _, quantity := range quantities {
+		value := items[quantity]
+		item, ok := oldItems[quantity]
+		if ok {
+			value = a.resolve(e.Line, model.PluralKey(key, quantity), item.Value(), value)
+			if value != item.Value() {
+				a.doc.ReplaceItem(item, value)
 			}
 			continue
 		}
 
-		keyAppended++
-		if !appendFlag {
-			appendFlag = true
-			newFileLines = append(newFileLines, "\n")
+		// keep items in CLDR order
+		a.stats.added(model.PluralKey(key, quantity))
+		text := a.format.Item([]xml.Attr{resxml.NewAttr("quantity", quantity)}, value)
+		var next *resxml.Item
+		for _, old := range e.Items {
+			if model.QuantityIndex(old.Attr("quantity")) > model.QuantityIndex(quantity) {
+				next = old
+				break
+			}
+		}
+		if next != nil {
+			a.doc.InsertItemBefore(next, text)
+		} else {
+			a.doc.AppendItem(e, text)
 		}
-		newFileLines = append(newFileLines, newLine)
 	}
 
-	sb := &strings.Builder{}
-	for _, l := range newFileLines {
-		sb.WriteString(l)
-		if l != "\n" {
-			sb.WriteRune('\n')
+	attrs := mergeAttrs(e.Attrs, srcAttrs)
+	if !attrsEqual(attrs, e.Attrs) {
+		a.toolsUsed = a.toolsUsed || usesTools(attrs)
+		a.doc.ReplaceStartTag(e, a.format.StartTag(e.Tag, key, attrs))
+	}
+}
+
+// checkArrays fails on string arrays with missing items, an item cannot be
+// written at its index if the ones before it are missing
+func checkArrays(data map[string]string) error {
+	_, _, arrays := groupResources(data)
+	for _, name := range sortedNames(nil, nil, arrays) {
+		indices := make([]int, 0, len(arrays[name]))
+		for index := range arrays[name] {
+			indices = append(indices, index)
+		}
+		sort.Ints(indices)
+		if i, ok := model.MissingArrayItem(indices); ok {
+			return fmt.Errorf("item %d of string-array '%v' is missing", i, name)
 		}
 	}
-	sb.WriteString("</resources>")
+	return nil
+}
 
-	if !dry {
-		err = ioutil.WriteFile(output, []byte(sb.String()), 0644)
+// sortedNames returns all resource names of grouped data, sorted
+func sortedNames(strs map[string]string, plurals map[string]map[string]string, arrays map[string]map[int]string) []string {
+	names := make(map[string]bool)
+	for key := range strs {
+		names[key] = true
+	}
+	for name := range plurals {
+		names[name] = true
+	}
+	for name := range arrays {
+		names[name] = true
 	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
 
+// groupResources splits data into plain strings, plurals and string arrays
+func groupResources(data map[string]string) (strs map[string]string, plurals map[string]map[string]string, arrays map[string]map[int]string) {
+	strs = make(map[string]string)
+	plurals = make(map[string]map[string]string)
+	arrays = make(map[string]map[int]string)
+	for key, value := range data {
+		name, selector := model.SplitResourceKey(key)
+		if selector != "" && model.IsPluralQuantity(selector) {
+			if plurals[name] == nil {
+				plurals[name] = make(map[string]string)
+			}
+			plurals[name][selector] = value
+		} else if index, ok := model.ArrayIndex(selector); ok {
+			if arrays[name] == nil {
+				arrays[name] = make(map[int]string)
+			}
+			arrays[name][index] = value
+		} else {
+			strs[key] = value
+		}
+	}
 	return
 }
@@ -0,0 +1,210 @@
+package appender
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// appendStaged appends data to a strings.xml holding raw and returns the
+// staged content of it
+func appendStaged(t *testing.T, raw string, data map[string]string, resolver CollisionResolver, opts ...AppendOpt) (string, error) {
+	t.Helper()
+	output := filepath.Join(t.TempDir(), "values", "strings.xml")
+	err := os.MkdirAll(filepath.Dir(output), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(output, []byte(raw), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	staging := make(map[string][]byte)
+	_, err = AppendToXML(data, output, resolver, false, append(opts, WithStaging(staging))...)
+	if err != nil {
+		return "", err
+	}
+	content, ok := staging[output]
+	if !ok {
+		return raw, nil
+	}
+	return string(content), nil
+}
+
+func TestAppendArray(t *testing.T) {
+	overwrite := func(file string, pos int, key, old, newer string) string { return newer }
+	tests := []struct {
+		name    string
+		raw     string
+		data    map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "new array",
+			raw:  "<resources>\n</resources>\n",
+			data: map[string]string{"a[1]": "B", "a[0]": "A"},
+			want: "<resources>\n    <string-array name=\"a\">\n        <item>A</item>\n        <item>B</item>\n    </string-array>\n</resources>\n",
+		},
+		{
+			name: "grown",
+			raw:  "<resources>\n    <string-array name=\"a\">\n        <item>A</item>\n    </string-array>\n</resources>\n",
+			data: map[string]string{"a[0]": "A", "a[1]": "B"},
+			want: "<resources>\n    <string-array name=\"a\">\n        <item>A</item>\n        <item>B</item>\n    </string-array>\n</resources>\n",
+		},
+		{
+			name: "shrunk",
+			raw:  "<resources>\n    <string-array name=\"a\">\n        <item>A</item>\n        <item>B</item>\n        <item>C</item>\n        <item>D</item>\n        <item>E</item>\n    </string-array>\n</resources>\n",
+			data: map[string]string{"a[0]": "A", "a[1]": "B2", "a[2]": "C"},
+			want: "<resources>\n    <string-array name=\"a\">\n        <item>A</item>\n        <item>B2</item>\n        <item>C</item>\n    </string-array>\n</resources>\n",
+		},
+		{
+			name: "shrunk on one line",
+			raw:  "<resources>\n    <string-array name=\"a\"><item>A</item><item>B</item></string-array>\n</resources>\n",
+			data: map[string]string{"a[0]": "A"},
+			want: "<resources>\n    <string-array name=\"a\"><item>A</item></string-array>\n</resources>\n",
+		},
+		{
+			name:    "gap",
+			raw:     "<resources>\n    <string-array name=\"a\">\n        <item>A</item>\n        <item>B</item>\n    </string-array>\n</resources>\n",
+			data:    map[string]string{"a[0]": "A", "a[1]": "B", "a[5]": "F"},
+			wantErr: "item 2 of string-array 'a' is missing",
+		},
+		{
+			name:    "no first item",
+			raw:     "<resources>\n</resources>\n",
+			data:    map[string]string{"a[1]": "B"},
+			wantErr: "item 0 of string-array 'a' is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendStaged(t, tt.raw, tt.data, overwrite)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("AppendToXML() err:%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("AppendToXML() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// parsedAppenders returns appenders of files by path, in the order of paths
+func parsedAppenders(t *testing.T, paths []string, files map[string]string) []*xmlAppender {
+	t.Helper()
+	appenders := make([]*xmlAppender, 0, len(paths))
+	for _, path := range paths {
+		doc, err := resxml.Parse([]byte(files[path]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		appenders = append(appenders, newXMLAppender(doc, path, nil, &appendOptions{}))
+	}
+	return appenders
+}
+
+func TestRouteResources(t *testing.T) {
+	paths := []string{"values/strings.xml", "values/arrays.xml", "values/plurals.xml"}
+	appenders := parsedAppenders(t, paths, map[string]string{
+		"values/strings.xml": "<resources>\n    <string name=\"title\">Title</string>\n</resources>\n",
+		"values/arrays.xml":  "<resources>\n    <string-array name=\"days\">\n        <item>Mon</item>\n    </string-array>\n    <string name=\"title\">Again</string>\n</resources>\n",
+		"values/plurals.xml": "<resources>\n    <plurals name=\"count\">\n        <item quantity=\"other\">#</item>\n    </plurals>\n    <string name=\"days\">Days</string>\n</resources>\n",
+	})
+	data := map[string]string{
+		"title":        "Title",
+		"days[0]":      "Mon",
+		"days[1]":      "Tue",
+		"count[one]":   "# item",
+		"count[other]": "# items",
+		"fresh":        "Fresh",
+		"fresh[few]":   "# fresh",
+	}
+
+	routed := routeResources(data, appenders)
+	want := map[string]string{
+		// the first definition of duplicates owns the resource
+		"values/strings.xml": "fresh,fresh[few],title",
+		"values/arrays.xml":  "days[0],days[1]",
+		"values/plurals.xml": "count[one],count[other]",
+	}
+	for _, a := range appenders {
+		keys := make([]string, 0, len(routed[a]))
+		for key := range routed[a] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if got := strings.Join(keys, ","); got != want[a.output] {
+			t.Errorf("routed to %v = %v, want %v", a.output, got, want[a.output])
+		}
+	}
+}
+
+func TestReportDuplicates(t *testing.T) {
+	paths := []string{"values/strings.xml", "values/extra.xml"}
+	appenders := parsedAppenders(t, paths, map[string]string{
+		"values/strings.xml": "<resources>\n    <string name=\"b\">B</string>\n    <string name=\"a\">A</string>\n    <string name=\"a\">A2</string>\n    <color name=\"c\">#fff</color>\n</resources>\n",
+		"values/extra.xml":   "<resources>\n    <string name=\"b\">B2</string>\n    <plurals name=\"a\">\n        <item quantity=\"other\">#</item>\n    </plurals>\n    <color name=\"c\">#000</color>\n</resources>\n",
+	})
+
+	var got []string
+	reportDuplicates(appenders, func(key string, locations []resdir.Location) {
+		var at []string
+		for _, l := range locations {
+			at = append(at, l.String())
+		}
+		got = append(got, key+" "+strings.Join(at, " "))
+	})
+	// plurals and strings of the same name do not collide, colors are not
+	// string resources
+	want := "b values/strings.xml:2 values/extra.xml:2,a values/strings.xml:3 values/strings.xml:4"
+	if strings.Join(got, ",") != want {
+		t.Errorf("reportDuplicates() = %v, want %v", strings.Join(got, ","), want)
+	}
+
+	// no handler, nothing to do
+	reportDuplicates(appenders, nil)
+}
+
+func TestAppendToDefiningFile(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "values")
+	output := filepath.Join(folder, "strings.xml")
+	extra := filepath.Join(folder, "extra.xml")
+	err := os.MkdirAll(folder, 0755)
+	if err == nil {
+		err = ioutil.WriteFile(output, []byte("<resources>\n    <string name=\"a\">A</string>\n</resources>\n"), 0644)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(extra, []byte("<resources>\n    <string name=\"b\">B</string>\n</resources>\n"), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staging := make(map[string][]byte)
+	overwrite := func(file string, pos int, key, old, newer string) string { return newer }
+	stats, err := AppendToXML(map[string]string{"a": "A", "b": "B2", "c": "C"}, output, overwrite, false, WithStaging(staging))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<resources>\n    <string name=\"a\">A</string>\n\n    <string name=\"c\">C</string>\n</resources>\n"; string(staging[output]) != want {
+		t.Errorf("%v =\n%q\nwant\n%q", output, staging[output], want)
+	}
+	if want := "<resources>\n    <string name=\"b\">B2</string>\n</resources>\n"; string(staging[extra]) != want {
+		t.Errorf("%v =\n%q\nwant\n%q", extra, staging[extra], want)
+	}
+	if stats.Appended != 1 || stats.Changed != 1 || stats.Unchanged != 1 {
+		t.Errorf("stats = %+v, want 1 appended, 1 changed, 1 unchanged", stats)
+	}
+}
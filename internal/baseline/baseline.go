@@ -0,0 +1,203 @@
+// Package baseline records accepted lint findings, so that only new findings
+// fail a run. Entries may expire, by rule or one by one
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+// DefaultPath is the baseline file used if it exists
+const DefaultPath = "lint-baseline.json"
+
+// Version is the version of the baseline file format
+const Version = 1
+
+// DateLayout is the layout of expiry dates, entries expire after the day
+const DateLayout = "2006-01-02"
+
+// Entry is an accepted finding of a rule for a key in a language
+type Entry struct {
+	Rule     string `json:"rule"`
+	Language string `json:"language"`
+	Key      string `json:"key"`
+	Message  string `json:"message,omitempty"`
+	// Expires is the last day the entry suppresses findings, empty for the
+	// expiry of its rule
+	Expires string `json:"expires,omitempty"`
+}
+
+func (e *Entry) id() string {
+	return e.Rule + "\x00" + e.Language + "\x00" + e.Key
+}
+
+func resultID(result *model.LintResult) string {
+	return result.Rule + "\x00" + result.Language + "\x00" + result.Key
+}
+
+// Baseline is the accepted findings of a project
+type Baseline struct {
+	Version int `json:"version"`
+	// Expires maps rule ids to the last day their entries suppress findings
+	Expires map[string]string `json:"expires,omitempty"`
+	Entries []*Entry          `json:"entries"`
+
+	path    string
+	entries map[string]*Entry
+	matched map[*Entry]bool
+}
+
+// New returns an empty baseline written to path
+func New(path string) *Baseline {
+	b := &Baseline{Version: Version, Entries: []*Entry{}, path: path}
+	b.index()
+	return b
+}
+
+// Load reads the baseline at path, a missing file is an empty baseline
+func Load(path string) (b *Baseline, err error) {
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(path), nil
+	} else if err != nil {
+		return
+	}
+	b = &Baseline{}
+	if err = json.Unmarshal(raw, b); err != nil {
+		return nil, fmt.Errorf("cannot parse %v, err:%v", path, err)
+	}
+	if b.Version > Version {
+		return nil, fmt.Errorf("%v has version %d, newer than %d", path, b.Version, Version)
+	}
+	for rule, date := range b.Expires {
+		if model.FindRule(rule) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q in expires of %v", rule, path)
+		}
+		if _, err = time.Parse(DateLayout, date); err != nil {
+			return nil, fmt.Errorf("invalid expiry date %q of %v in %v, use %v", date, rule, path, DateLayout)
+		}
+	}
+	for _, e := range b.Entries {
+		if e.Expires == "" {
+			continue
+		}
+		if _, err = time.Parse(DateLayout, e.Expires); err != nil {
+			return nil, fmt.Errorf("invalid expiry date %q of %v, lang:%v, key:%v in %v, use %v", e.Expires, e.Rule, e.Language, e.Key, path, DateLayout)
+		}
+	}
+	b.path = path
+	b.index()
+	return
+}
+
+func (b *Baseline) index() {
+	b.entries = make(map[string]*Entry, len(b.Entries))
+	b.matched = make(map[*Entry]bool)
+	for _, e := range b.Entries {
+		b.entries[e.id()] = e
+	}
+}
+
+// Path returns the path of the baseline file
+func (b *Baseline) Path() string {
+	return b.path
+}
+
+// ExpiresOn returns the last day e suppresses findings, empty for never
+func (b *Baseline) ExpiresOn(e *Entry) string {
+	if e.Expires != "" {
+		return e.Expires
+	}
+	return b.Expires[e.Rule]
+}
+
+// Suppress splits results into the ones kept and the ones in the baseline,
+// results of entries expired by now are kept, expired holds those entries
+func (b *Baseline) Suppress(results []*model.LintResult, now time.Time) (kept, suppressed []*model.LintResult, expired []*Entry) {
+	today := now.Format(DateLayout)
+	seen := make(map[*Entry]bool)
+	for _, result := range results {
+		e, ok := b.entries[resultID(result)]
+		if !ok {
+			kept = append(kept, result)
+			continue
+		}
+		b.matched[e] = true
+		if date := b.ExpiresOn(e); date != "" && date < today {
+			kept = append(kept, result)
+			if !seen[e] {
+				seen[e] = true
+				expired = append(expired, e)
+			}
+			continue
+		}
+		suppressed = append(suppressed, result)
+	}
+	return
+}
+
+// Stale returns the entries no result of Suppress matched
+func (b *Baseline) Stale() (stale []*Entry) {
+	for _, e := range b.Entries {
+		if !b.matched[e] {
+			stale = append(stale, e)
+		}
+	}
+	return
+}
+
+// Update returns a baseline of results with the rule expiry dates of b,
+// entries already in b keep their expiry dates
+func (b *Baseline) Update(results []*model.LintResult) *Baseline {
+	updated := New(b.path)
+	for rule, date := range b.Expires {
+		if updated.Expires == nil {
+			updated.Expires = make(map[string]string)
+		}
+		updated.Expires[rule] = date
+	}
+	for _, result := range results {
+		id := resultID(result)
+		if _, ok := updated.entries[id]; ok {
+			continue
+		}
+		e := &Entry{
+			Rule:     result.Rule,
+			Language: result.Language,
+			Key:      result.Key,
+			Message:  result.Desc,
+		}
+		if old, ok := b.entries[id]; ok {
+			e.Expires = old.Expires
+		}
+		updated.entries[id] = e
+		updated.Entries = append(updated.Entries, e)
+	}
+	sort.Slice(updated.Entries, func(i, j int) bool {
+		a, c := updated.Entries[i], updated.Entries[j]
+		if a.Key != c.Key {
+			return a.Key < c.Key
+		}
+		if a.Language != c.Language {
+			return a.Language < c.Language
+		}
+		return a.Rule < c.Rule
+	})
+	return updated
+}
+
+// Write writes the baseline to its path
+func (b *Baseline) Write() error {
+	raw, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+	return ioutil.WriteFile(b.path, raw, 0644)
+}
@@ -0,0 +1,223 @@
+package baseline
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+func writeBaseline(t *testing.T, raw string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := ioutil.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// describe returns results like "rule lang key"
+func describe(results []*model.LintResult) (ids []string) {
+	for _, r := range results {
+		ids = append(ids, r.Rule+" "+r.Language+" "+r.Key)
+	}
+	return
+}
+
+func entryKeys(entries []*Entry) (keys []string) {
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	return
+}
+
+func TestLoad(t *testing.T) {
+	b, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(b.Entries) != 0 {
+		t.Errorf("Load() of a missing file = %v, err:%v, want an empty baseline", b, err)
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{name: "valid", raw: `{"version": 1, "expires": {"placeholder-missing": "2026-01-31"}, "entries": [{"rule": "placeholder-extra", "language": "fr", "key": "a", "expires": "2026-02-01"}]}`},
+		{name: "not json", raw: `entries`, wantErr: "cannot parse"},
+		{name: "newer version", raw: `{"version": 2, "entries": []}`, wantErr: "newer than 1"},
+		{name: "unknown rule expiry", raw: `{"version": 1, "expires": {"no-such-rule": "2026-01-31"}}`, wantErr: `unknown lint rule "no-such-rule"`},
+		{name: "invalid rule expiry", raw: `{"version": 1, "expires": {"placeholder-missing": "31/01/2026"}}`, wantErr: "invalid expiry date"},
+		{name: "invalid entry expiry", raw: `{"version": 1, "entries": [{"rule": "placeholder-extra", "language": "fr", "key": "a", "expires": "soon"}]}`, wantErr: "invalid expiry date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeBaseline(t, tt.raw))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Load() err:%v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() err:%v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	path := writeBaseline(t, `{
+  "version": 1,
+  "expires": {"placeholder-missing": "2026-03-31"},
+  "entries": [
+    {"rule": "placeholder-extra", "language": "fr", "key": "a"},
+    {"rule": "placeholder-extra", "language": "fr", "key": "b", "expires": "2026-03-01"},
+    {"rule": "placeholder-missing", "language": "de", "key": "c"},
+    {"rule": "placeholder-missing", "language": "de", "key": "d", "expires": "2026-12-31"},
+    {"rule": "placeholder-type", "language": "fr", "key": "gone"}
+  ]
+}`)
+	results := []*model.LintResult{
+		{Rule: "placeholder-extra", Language: "fr", Key: "a"},
+		// same key, other language and other rule are new findings
+		{Rule: "placeholder-extra", Language: "de", Key: "a"},
+		{Rule: "placeholder-type", Language: "fr", Key: "a"},
+		{Rule: "placeholder-extra", Language: "fr", Key: "b"},
+		{Rule: "placeholder-missing", Language: "de", Key: "c"},
+		{Rule: "placeholder-missing", Language: "de", Key: "d"},
+	}
+
+	tests := []struct {
+		name       string
+		now        string
+		kept       []string
+		suppressed []string
+		expired    []string
+	}{
+		{
+			name:       "nothing expired",
+			now:        "2026-02-15",
+			kept:       []string{"placeholder-extra de a", "placeholder-type fr a"},
+			suppressed: []string{"placeholder-extra fr a", "placeholder-extra fr b", "placeholder-missing de c", "placeholder-missing de d"},
+		},
+		{
+			name:       "expiry day still suppresses",
+			now:        "2026-03-01",
+			kept:       []string{"placeholder-extra de a", "placeholder-type fr a"},
+			suppressed: []string{"placeholder-extra fr a", "placeholder-extra fr b", "placeholder-missing de c", "placeholder-missing de d"},
+		},
+		{
+			name:       "entry expired",
+			now:        "2026-03-02",
+			kept:       []string{"placeholder-extra de a", "placeholder-type fr a", "placeholder-extra fr b"},
+			suppressed: []string{"placeholder-extra fr a", "placeholder-missing de c", "placeholder-missing de d"},
+			expired:    []string{"b"},
+		},
+		{
+			name:       "rule expired, entry expiry takes precedence",
+			now:        "2026-04-01",
+			kept:       []string{"placeholder-extra de a", "placeholder-type fr a", "placeholder-extra fr b", "placeholder-missing de c"},
+			suppressed: []string{"placeholder-extra fr a", "placeholder-missing de d"},
+			expired:    []string{"b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now, err := time.Parse(DateLayout, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// late in the day, expiry is by date
+			kept, suppressed, expired := b.Suppress(results, now.Add(23*time.Hour))
+			if got := describe(kept); !reflect.DeepEqual(got, tt.kept) {
+				t.Errorf("Suppress() kept %v, want %v", got, tt.kept)
+			}
+			if got := describe(suppressed); !reflect.DeepEqual(got, tt.suppressed) {
+				t.Errorf("Suppress() suppressed %v, want %v", got, tt.suppressed)
+			}
+			if got := entryKeys(expired); !reflect.DeepEqual(got, tt.expired) {
+				t.Errorf("Suppress() expired %v, want %v", got, tt.expired)
+			}
+			if got := entryKeys(b.Stale()); !reflect.DeepEqual(got, []string{"gone"}) {
+				t.Errorf("Stale() = %v, want [gone]", got)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := writeBaseline(t, `{
+  "version": 1,
+  "expires": {"placeholder-missing": "2026-03-31"},
+  "entries": [
+    {"rule": "placeholder-extra", "language": "fr", "key": "b", "message": "old", "expires": "2026-03-01"},
+    {"rule": "placeholder-type", "language": "fr", "key": "gone"}
+  ]
+}`)
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := b.Update([]*model.LintResult{
+		{Rule: "placeholder-missing", Language: "de", Key: "c", Desc: "c missing"},
+		{Rule: "placeholder-extra", Language: "fr", Key: "b", Desc: "b extra"},
+		{Rule: "placeholder-extra", Language: "de", Key: "b", Desc: "b extra"},
+		// reported twice, recorded once
+		{Rule: "placeholder-missing", Language: "de", Key: "c", Desc: "c missing"},
+	})
+	if err = updated.Write(); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "version": 1,
+  "expires": {
+    "placeholder-missing": "2026-03-31"
+  },
+  "entries": [
+    {
+      "rule": "placeholder-extra",
+      "language": "de",
+      "key": "b",
+      "message": "b extra"
+    },
+    {
+      "rule": "placeholder-extra",
+      "language": "fr",
+      "key": "b",
+      "message": "b extra",
+      "expires": "2026-03-01"
+    },
+    {
+      "rule": "placeholder-missing",
+      "language": "de",
+      "key": "c",
+      "message": "c missing"
+    }
+  ]
+}
+`
+	if string(raw) != want {
+		t.Errorf("Update() wrote\n%s\nwant\n%s", raw, want)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, suppressed, _ := reloaded.Suppress([]*model.LintResult{{Rule: "placeholder-missing", Language: "de", Key: "c"}}, time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC))
+	if len(suppressed) != 1 {
+		t.Errorf("updated baseline does not suppress its entry")
+	}
+}
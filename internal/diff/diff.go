@@ -0,0 +1,252 @@
+// Package diff creates unified diffs of text files that apply with git apply
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines around each hunk
+const Context = 3
+
+type op struct {
+	kind byte
+	text string
+}
+
+// splitLines splits content into lines, keeping line terminators
+func splitLines(content []byte) []string {
+	var lines []string
+	for len(content) > 0 {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			lines = append(lines, string(content))
+			break
+		}
+		lines = append(lines, string(content[:i+1]))
+		content = content[i+1:]
+	}
+	return lines
+}
+
+// Unified returns the git style diff of a file at path, from old to newer.
+// old is nil for a new file, newer is nil for a deleted one, the diff is
+// empty if nothing changed
+func Unified(path string, old, newer []byte) string {
+	if (old == nil) == (newer == nil) && bytes.Equal(old, newer) {
+		return ""
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "diff --git a/%v b/%v\n", path, path)
+	switch {
+	case old == nil:
+		sb.WriteString("new file mode 100644\n")
+		sb.WriteString("--- /dev/null\n")
+		fmt.Fprintf(sb, "+++ b/%v\n", path)
+	case newer == nil:
+		sb.WriteString("deleted file mode 100644\n")
+		fmt.Fprintf(sb, "--- a/%v\n", path)
+		sb.WriteString("+++ /dev/null\n")
+	default:
+		fmt.Fprintf(sb, "--- a/%v\n", path)
+		fmt.Fprintf(sb, "+++ b/%v\n", path)
+	}
+
+	ops := lineOps(splitLines(old), splitLines(newer))
+	oldBefore := make([]int, len(ops)+1)
+	newBefore := make([]int, len(ops)+1)
+	for i, v := range ops {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if v.kind != '+' {
+			oldBefore[i+1]++
+		}
+		if v.kind != '-' {
+			newBefore[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - Context
+		if start < 0 {
+			start = 0
+		}
+		last := i
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind == ' ' {
+				continue
+			}
+			if j-last-1 > 2*Context {
+				break
+			}
+			last = j
+		}
+		stop := last + Context + 1
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		fmt.Fprintf(sb, "@@ -%v +%v @@\n",
+			hunkRange(oldBefore[start], oldBefore[stop]-oldBefore[start]),
+			hunkRange(newBefore[start], newBefore[stop]-newBefore[start]))
+		for _, v := range ops[start:stop] {
+			sb.WriteByte(v.kind)
+			sb.WriteString(v.text)
+			if !strings.HasSuffix(v.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+
+	return sb.String()
+}
+
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+// lineOps returns the shortest edit script from a to b, see "An O(ND)
+// Difference Algorithm and Its Variations" by Eugene W. Myers
+func lineOps(a, b []string) []op {
+	// common prefix and suffix are kept as is
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, v := range a[:prefix] {
+		ops = append(ops, op{kind: ' ', text: v})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, v := range a[len(a)-suffix:] {
+		ops = append(ops, op{kind: ' ', text: v})
+	}
+	return ops
+}
+
+func myers(a, b []string) []op {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds v[-d-1 ... d+1] before round d
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= n+m && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var reversed []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int {
+			return prev[k+d+1]
+		}
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, op{kind: ' ', text: a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			reversed = append(reversed, op{kind: '+', text: b[prevY]})
+		} else {
+			reversed = append(reversed, op{kind: '-', text: a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+
+	ops := make([]op, len(reversed))
+	for i, v := range reversed {
+		ops[len(reversed)-1-i] = v
+	}
+	return ops
+}
+
+// Colorize colors the lines of a unified diff for terminals
+func Colorize(patch string) string {
+	const (
+		reset = "\x1b[0m"
+		bold  = "\x1b[1m"
+		red   = "\x1b[31m"
+		green = "\x1b[32m"
+		cyan  = "\x1b[36m"
+	)
+
+	sb := &strings.Builder{}
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(text, "diff --git"), strings.HasPrefix(text, "new file"), strings.HasPrefix(text, "deleted file"),
+			strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+			color = bold
+		case strings.HasPrefix(text, "@@"):
+			color = cyan
+		case strings.HasPrefix(text, "+"):
+			color = green
+		case strings.HasPrefix(text, "-"):
+			color = red
+		}
+		if color == "" {
+			sb.WriteString(line)
+			continue
+		}
+		sb.WriteString(color + text + reset)
+		if strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numbered returns lines "1" to "n", each line ending with a newline
+func numbered(n int, replace map[int]string) []byte {
+	sb := &strings.Builder{}
+	for i := 1; i <= n; i++ {
+		if v, ok := replace[i]; ok {
+			sb.WriteString(v + "\n")
+		} else {
+			fmt.Fprintf(sb, "%d\n", i)
+		}
+	}
+	return []byte(sb.String())
+}
+
+func hunkHeaders(patch string) (headers []string) {
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			headers = append(headers, line)
+		}
+	}
+	return
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      []byte
+		newer    []byte
+		headers  []string
+		contains []string
+	}{
+		{
+			name:    "nothing changed",
+			old:     numbered(3, nil),
+			newer:   numbered(3, nil),
+			headers: nil,
+		},
+		{
+			name:     "one line changed",
+			old:      numbered(10, nil),
+			newer:    numbered(10, map[int]string{5: "five"}),
+			headers:  []string{"@@ -2,7 +2,7 @@"},
+			contains: []string{"--- a/f.xml\n+++ b/f.xml\n", "-5\n+five\n"},
+		},
+		{
+			name:    "close changes share a hunk",
+			old:     numbered(20, nil),
+			newer:   numbered(20, map[int]string{4: "four", 10: "ten"}),
+			headers: []string{"@@ -1,13 +1,13 @@"},
+		},
+		{
+			name:    "far changes get their own hunks",
+			old:     numbered(30, nil),
+			newer:   numbered(30, map[int]string{4: "four", 20: "twenty"}),
+			headers: []string{"@@ -1,7 +1,7 @@", "@@ -17,7 +17,7 @@"},
+		},
+		{
+			name:     "lines appended",
+			old:      numbered(2, nil),
+			newer:    numbered(4, nil),
+			headers:  []string{"@@ -1,2 +1,4 @@"},
+			contains: []string{" 2\n+3\n+4\n"},
+		},
+		{
+			name:     "no newline at end of file",
+			old:      []byte("a\nb"),
+			newer:    []byte("a\nc"),
+			headers:  []string{"@@ -1,2 +1,2 @@"},
+			contains: []string{"-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n"},
+		},
+		{
+			name:     "final newline added",
+			old:      []byte("a\nb"),
+			newer:    []byte("a\nb\n"),
+			headers:  []string{"@@ -1,2 +1,2 @@"},
+			contains: []string{"-b\n\\ No newline at end of file\n+b\n"},
+		},
+		{
+			name:     "new file",
+			old:      nil,
+			newer:    []byte("a\nb\n"),
+			headers:  []string{"@@ -0,0 +1,2 @@"},
+			contains: []string{"new file mode 100644\n--- /dev/null\n+++ b/f.xml\n", "+a\n+b\n"},
+		},
+		{
+			name:     "deleted file",
+			old:      []byte("a\nb\n"),
+			newer:    nil,
+			headers:  []string{"@@ -1,2 +0,0 @@"},
+			contains: []string{"deleted file mode 100644\n--- a/f.xml\n+++ /dev/null\n", "-a\n-b\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := Unified("f.xml", tt.old, tt.newer)
+			if tt.headers == nil {
+				if patch != "" {
+					t.Errorf("Unified() = %q, want empty", patch)
+				}
+				return
+			}
+			got := hunkHeaders(patch)
+			if strings.Join(got, "|") != strings.Join(tt.headers, "|") {
+				t.Errorf("hunks = %v, want %v\n%v", got, tt.headers, patch)
+			}
+			for _, v := range tt.contains {
+				if !strings.Contains(patch, v) {
+					t.Errorf("Unified() misses %q\n%v", v, patch)
+				}
+			}
+		})
+	}
+}
+
+// TestGitApply checks patches apply with git apply and give the new content
+func TestGitApply(t *testing.T) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not found")
+	}
+
+	tests := []struct {
+		name  string
+		old   []byte
+		newer []byte
+	}{
+		{"changed", numbered(40, nil), numbered(42, map[int]string{3: "three", 20: "twenty", 39: "x"})},
+		{"removed lines", numbered(12, nil), []byte("1\n2\n11\n12\n")},
+		{"no newline at end of file", []byte("a\nb"), []byte("a\nc")},
+		{"crlf", []byte("<a>\r\n<b/>\r\n</a>\r\n"), []byte("<a>\r\n<b/>\r\n<c/>\r\n</a>\r\n")},
+		{"new file", nil, []byte("a\nb\n")},
+		{"deleted file", []byte("a\nb\n"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "res", "values", "strings.xml")
+			if tt.old != nil {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(path, tt.old, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			patch := filepath.Join(dir, "out.diff")
+			if err := ioutil.WriteFile(patch, []byte(Unified("res/values/strings.xml", tt.old, tt.newer)), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := exec.Command(git, "apply", "out.diff")
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git apply err:%v\n%s", err, out)
+			}
+			got, err := ioutil.ReadFile(path)
+			if tt.newer == nil {
+				if !os.IsNotExist(err) {
+					t.Errorf("file not deleted, err:%v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(tt.newer) {
+				t.Errorf("applied = %q, want %q", got, tt.newer)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package escape
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Android escapes text for <string> and <item> values of android resource
+// files. <b>, <i>, <u> and <annotation> tags in text are kept as markup, any
+// other '<' is text. Whitespace that android would collapse or trim is kept
+// by double quoting
+var Android Escaper = androidEscaper{}
+
+// androidTag matches an allowed tag at the start of text
+var androidTag = regexp.MustCompile(`^(?:</?[biu]>|<annotation(?:\s+[A-Za-z_:][-\w:.]*\s*=\s*"[^"<&]*")*\s*>|</annotation>)`)
+
+type androidEscaper struct{}
+
+type segment struct {
+	text string
+	tag  bool
+}
+
+// splitTags splits text into text and allowed tag segments
+func splitTags(text string) (segments []segment) {
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '<' {
+			continue
+		}
+		tag := androidTag.FindString(text[i:])
+		if tag == "" {
+			continue
+		}
+		if i > start {
+			segments = append(segments, segment{text: text[start:i]})
+		}
+		segments = append(segments, segment{text: tag, tag: true})
+		i += len(tag) - 1
+		start = i + 1
+	}
+	if start < len(text) {
+		segments = append(segments, segment{text: text[start:]})
+	}
+	return
+}
+
+func (androidEscaper) Escape(text string) string {
+	segments := splitTags(text)
+
+	// android trims leading and trailing spaces and collapses runs of them
+	plain := &strings.Builder{}
+	for _, seg := range segments {
+		if !seg.tag {
+			plain.WriteString(seg.text)
+		}
+	}
+	quote := strings.HasPrefix(plain.String(), " ") ||
+		strings.HasSuffix(plain.String(), " ") ||
+		strings.Contains(plain.String(), "  ")
+
+	sb := &strings.Builder{}
+	for i, seg := range segments {
+		if seg.tag {
+			sb.WriteString(seg.text)
+			continue
+		}
+		quoted := quote && strings.Contains(seg.text, " ")
+		if quoted {
+			sb.WriteByte('"')
+		}
+		for j, r := range seg.text {
+			switch r {
+			case '\\':
+				sb.WriteString(`\\`)
+			case '"':
+				sb.WriteString(`\"`)
+			case '\'':
+				if quoted {
+					sb.WriteRune(r)
+				} else {
+					sb.WriteString(`\'`)
+				}
+			case '\n':
+				sb.WriteString(`\n`)
+			case '\t':
+				sb.WriteString(`\t`)
+			case '@', '?':
+				// a leading @ or ? makes a resource reference
+				if i == 0 && j == 0 {
+					sb.WriteByte('\\')
+				}
+				sb.WriteRune(r)
+			case '&':
+				sb.WriteString("&amp;")
+			case '<':
+				sb.WriteString("&lt;")
+			case '>':
+				sb.WriteString("&gt;")
+			default:
+				if needsUnicodeEscape(r) {
+					fmt.Fprintf(sb, `\u%04x`, r)
+				} else {
+					sb.WriteRune(r)
+				}
+			}
+		}
+		if quoted {
+			sb.WriteByte('"')
+		}
+	}
+	return sb.String()
+}
+
+// xmlChar is a character of the xml content, or markup kept as is
+type xmlChar struct {
+	r      rune
+	markup string
+}
+
+// decodeXML decodes entities and CDATA sections of raw xml content, tags
+// are kept as markup and comments are dropped
+func decodeXML(raw string) (chars []xmlChar, err error) {
+	for i := 0; i < len(raw); {
+		switch {
+		case strings.HasPrefix(raw[i:], "<!--"):
+			end := strings.Index(raw[i+4:], "-->")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at %d", i)
+			}
+			i += 4 + end + 3
+		case strings.HasPrefix(raw[i:], "<![CDATA["):
+			end := strings.Index(raw[i+9:], "]]>")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated CDATA at %d", i)
+			}
+			for _, r := range raw[i+9 : i+9+end] {
+				chars = append(chars, xmlChar{r: r})
+			}
+			i += 9 + end + 3
+		case raw[i] == '<':
+			end := tagEnd(raw[i:])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated tag at %d", i)
+			}
+			chars = append(chars, xmlChar{markup: raw[i : i+end]})
+			i += end
+		case raw[i] == '&':
+			end := strings.IndexByte(raw[i:], ';')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated entity at %d", i)
+			}
+			r, ok := decodeEntity(raw[i+1 : i+end])
+			if !ok {
+				return nil, fmt.Errorf("unknown entity %v at %d", raw[i:i+end+1], i)
+			}
+			chars = append(chars, xmlChar{r: r})
+			i += end + 1
+		default:
+			r, n := utf8.DecodeRuneInString(raw[i:])
+			chars = append(chars, xmlChar{r: r})
+			i += n
+		}
+	}
+	return
+}
+
+// tagEnd returns the length of the tag at the start of s, -1 if it has no
+// end, '>' in quoted attribute values does not end it
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '>':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func decodeEntity(name string) (rune, bool) {
+	switch name {
+	case "amp":
+		return '&', true
+	case "lt":
+		return '<', true
+	case "gt":
+		return '>', true
+	case "quot":
+		return '"', true
+	case "apos":
+		return '\'', true
+	}
+	if strings.HasPrefix(name, "#x") || strings.HasPrefix(name, "#X") {
+		v, err := strconv.ParseUint(name[2:], 16, 32)
+		return rune(v), err == nil
+	}
+	if strings.HasPrefix(name, "#") {
+		v, err := strconv.ParseUint(name[1:], 10, 32)
+		return rune(v), err == nil
+	}
+	return 0, false
+}
+
+func (androidEscaper) Unescape(raw string) (string, error) {
+	chars, err := decodeXML(raw)
+	if err != nil {
+		return "", err
+	}
+
+	type output struct {
+		text string
+		// space is whitespace android collapses and trims
+		space bool
+	}
+	var out []output
+	quoted := false
+	for i := 0; i < len(chars); i++ {
+		c := chars[i]
+		switch {
+		case c.markup != "":
+			out = append(out, output{text: c.markup})
+		case c.r == '\\':
+			if i+1 >= len(chars) || chars[i+1].markup != "" {
+				return "", fmt.Errorf("dangling backslash in %q", raw)
+			}
+			i++
+			switch chars[i].r {
+			case 'n':
+				out = append(out, output{text: "\n"})
+			case 't':
+				out = append(out, output{text: "\t"})
+			case 'u':
+				hex := &strings.Builder{}
+				for j := i + 1; j < len(chars) && j <= i+4 && chars[j].markup == ""; j++ {
+					hex.WriteRune(chars[j].r)
+				}
+				v, err := strconv.ParseUint(hex.String(), 16, 32)
+				if hex.Len() != 4 || err != nil {
+					return "", fmt.Errorf("invalid unicode escape \\u%v in %q", hex, raw)
+				}
+				out = append(out, output{text: string(rune(v))})
+				i += 4
+			default:
+				out = append(out, output{text: string(chars[i].r)})
+			}
+		case c.r == '"':
+			quoted = !quoted
+		case c.r == '\'' && !quoted:
+			return "", fmt.Errorf("apostrophe not preceded by \\ in %q", raw)
+		case !quoted && (c.r == ' ' || c.r == '\n' || c.r == '\t' || c.r == '\r'):
+			if len(out) == 0 || !out[len(out)-1].space {
+				out = append(out, output{text: " ", space: true})
+			}
+		default:
+			out = append(out, output{text: string(c.r)})
+		}
+	}
+	if quoted {
+		return "", fmt.Errorf("unterminated quote in %q", raw)
+	}
+
+	for len(out) > 0 && out[0].space {
+		out = out[1:]
+	}
+	for len(out) > 0 && out[len(out)-1].space {
+		out = out[:len(out)-1]
+	}
+	sb := &strings.Builder{}
+	for _, v := range out {
+		sb.WriteString(v.text)
+	}
+	return sb.String(), nil
+}
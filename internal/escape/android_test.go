@@ -0,0 +1,115 @@
+package escape
+
+import (
+	"testing"
+)
+
+func TestAndroidEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain", text: "hello", want: "hello"},
+		{name: "apostrophe", text: "it's", want: `it\'s`},
+		{name: "quote after multibyte rune", text: `中文"引号'`, want: `中文\"引号\'`},
+		{name: "apostrophe after emoji", text: "🙂'", want: `🙂\'`},
+		{name: "backslash", text: `a\b`, want: `a\\b`},
+		{name: "newline and tab", text: "a\nb\tc", want: `a\nb\tc`},
+		{name: "control character", text: "a\x01b", want: `a\u0001b`},
+		{name: "line separator", text: "a\u2028b", want: `a\u2028b`},
+		{name: "leading space", text: " a", want: `" a"`},
+		{name: "trailing space", text: "a ", want: `"a "`},
+		{name: "double space", text: "a  b", want: `"a  b"`},
+		{name: "apostrophe in quotes", text: " it's", want: `" it's"`},
+		{name: "quote in quotes", text: ` "a"`, want: `" \"a\""`},
+		{name: "leading at", text: "@me", want: `\@me`},
+		{name: "leading question mark", text: "?x", want: `\?x`},
+		{name: "inner at", text: "to @me", want: "to @me"},
+		{name: "entities", text: "a & b < c > d", want: "a &amp; b &lt; c &gt; d"},
+		{name: "allowed tags", text: "<b>bold</b> <i>i</i> <u>u</u>", want: "<b>bold</b> <i>i</i> <u>u</u>"},
+		{name: "annotation", text: `<annotation font="title">x</annotation>`, want: `<annotation font="title">x</annotation>`},
+		{name: "unknown tag is text", text: "<p>x</p>", want: "&lt;p&gt;x&lt;/p&gt;"},
+		{name: "broken tag is text", text: "<b", want: "&lt;b"},
+		{name: "spaces inside tags", text: "<b> a</b>", want: `<b>" a"</b>`},
+		{name: "cdata end is text", text: "]]>", want: "]]&gt;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Android.Escape(tt.text)
+			if got != tt.want {
+				t.Errorf("Escape(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			text, err := Android.Unescape(got)
+			if err != nil {
+				t.Fatalf("Unescape(%q) err:%v", got, err)
+			}
+			if text != tt.text {
+				t.Errorf("Unescape(%q) = %q, want %q", got, text, tt.text)
+			}
+		})
+	}
+}
+
+func TestAndroidUnescape(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "collapsed spaces", raw: "  a \n  b  ", want: "a b"},
+		{name: "quoted spaces", raw: `"  a  "`, want: "  a  "},
+		{name: "unicode escape", raw: `\u00a0x`, want: "\u00a0x"},
+		{name: "short unicode escape", raw: `\u00a`, wantErr: true},
+		{name: "escaped quotes", raw: `\"a\'`, want: `"a'`},
+		{name: "bare apostrophe", raw: "it's", wantErr: true},
+		{name: "unterminated quote", raw: `"a`, wantErr: true},
+		{name: "dangling backslash", raw: `a\`, wantErr: true},
+		{name: "cdata", raw: "<![CDATA[<b>a & b]]>", want: "<b>a & b"},
+		{name: "cdata with spaces", raw: "<![CDATA[ a ]]>", want: "a"},
+		{name: "comment dropped", raw: "a<!-- note -->b", want: "ab"},
+		{name: "numeric entities", raw: "&#65;&#x42;", want: "AB"},
+		{name: "unknown entity", raw: "&nbsp;", wantErr: true},
+		{name: "tags kept", raw: `<b>a</b> <annotation key=">">b</annotation>`, want: `<b>a</b> <annotation key=">">b</annotation>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Android.Unescape(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unescape(%q) err:%v, want error %v", tt.raw, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Unescape(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceText(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{cell: `a\nb`, want: "a\nb"},
+		{cell: `\'\"\\`, want: `'"\`},
+		{cell: `é`, want: "é"},
+		{cell: `\x`, want: `\x`},
+		{cell: `\u12`, want: `\u12`},
+		{cell: `a\`, want: `a\`},
+	}
+	for _, tt := range tests {
+		if got := SourceText(tt.cell); got != tt.want {
+			t.Errorf("SourceText(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestSame(t *testing.T) {
+	if !Same(Android, `it\'s`, `"it's"`) {
+		t.Error(`\' and "'" read differently`)
+	}
+	if Same(Android, "a", "b") {
+		t.Error("a and b read the same")
+	}
+}
@@ -0,0 +1,88 @@
+// Package escape converts text to the string formats of output targets and
+// back. Each target format has its own Escaper, text is what users read,
+// markup tags allowed by the format included.
+//
+// Android resources are the only target with an Escaper: the JSON output is
+// escaped by encoding/json, and strings of binary bundles are length prefixed
+// and stored as is, so neither has a format of its own to escape to
+package escape
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Escaper converts text to a target format. Unescape(Escape(text)) returns
+// text for any text
+type Escaper interface {
+	// Escape returns text in the target format
+	Escape(text string) string
+	// Unescape returns the text of a string in the target format
+	Unescape(raw string) (string, error)
+}
+
+// SourceText returns the text of a source cell. Backslash escapes written by
+// translators, like \n, \' or \u00a0, are decoded, other backslashes are kept
+func SourceText(cell string) string {
+	if !strings.Contains(cell, `\`) {
+		return cell
+	}
+	sb := &strings.Builder{}
+	for i := 0; i < len(cell); i++ {
+		c := cell[i]
+		if c != '\\' || i+1 >= len(cell) {
+			sb.WriteByte(c)
+			continue
+		}
+		if r, n, ok := decodeBackslash(cell[i:]); ok {
+			sb.WriteRune(r)
+			i += n - 1
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// decodeBackslash decodes the backslash escape at the start of s, n is the
+// length of the escape
+func decodeBackslash(s string) (r rune, n int, ok bool) {
+	if len(s) < 2 || s[0] != '\\' {
+		return 0, 0, false
+	}
+	switch s[1] {
+	case 'n':
+		return '\n', 2, true
+	case 't':
+		return '\t', 2, true
+	case '\\', '\'', '"', '@', '?':
+		return rune(s[1]), 2, true
+	case 'u':
+		if len(s) < 6 {
+			return 0, 0, false
+		}
+		v, err := strconv.ParseUint(s[2:6], 16, 32)
+		if err != nil {
+			return 0, 0, false
+		}
+		return rune(v), 6, true
+	}
+	return 0, 0, false
+}
+
+// Same reports whether two strings in the format of escaper read the same
+func Same(escaper Escaper, a, b string) bool {
+	textA, err := escaper.Unescape(a)
+	if err != nil {
+		return false
+	}
+	textB, err := escaper.Unescape(b)
+	return err == nil && textA == textB
+}
+
+// needsUnicodeEscape reports whether r is invisible or breaks lines, and is
+// written as \uXXXX
+func needsUnicodeEscape(r rune) bool {
+	return (r < 0x20 && r != '\n' && r != '\t') || r == 0x7f || r == 0x2028 || r == 0x2029 || r == utf8.RuneError
+}
@@ -0,0 +1,40 @@
+//go:build go1.18
+// +build go1.18
+
+package escape
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"it's",
+		` "quoted" `,
+		"a  b\n\tc",
+		"@me",
+		"中文'🙂",
+		"<b>bold</b> <i> i </i>",
+		`<annotation font="title">x</annotation>`,
+		"<p>a & b</p>",
+		"]]>\\u00a0",
+		"\x00\u2028\ufffd",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			t.Skip()
+		}
+		escaped := Android.Escape(s)
+		text, err := Android.Unescape(escaped)
+		if err != nil {
+			t.Fatalf("Unescape(Escape(%q)) = Unescape(%q) err:%v", s, escaped, err)
+		}
+		if text != s {
+			t.Fatalf("Unescape(Escape(%q)) = %q, escaped %q", s, text, escaped)
+		}
+	})
+}
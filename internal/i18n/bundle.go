@@ -0,0 +1,169 @@
+package i18n
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/master-g/i18n/pkg/bundle"
+)
+
+type bundleEntry struct {
+	hash  uint64
+	key   string
+	value string
+}
+
+// WriteBundle writes strings in the binary bundle format, see package bundle
+func WriteBundle(w io.Writer, meta *Metadata, data map[string]map[string]string, compression bundle.Compression) (err error) {
+	le := binary.LittleEndian
+
+	var metaRaw []byte
+	if meta != nil {
+		metaRaw, err = json.Marshal(&bundle.Metadata{
+			Version:     meta.Version,
+			SourceFiles: meta.SourceFiles,
+			CreatedAt:   meta.CreatedAt,
+			ModifiedAt:  meta.ModifiedAt,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	languages := make([]string, 0, len(data))
+	for lang := range data {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	// build sections first, the directory needs their sizes
+	sections := make([][]byte, 0, len(languages))
+	rawSizes := make([]int, 0, len(languages))
+	for _, lang := range languages {
+		var raw, stored []byte
+		raw, err = buildStringTable(data[lang])
+		if err != nil {
+			return fmt.Errorf("cannot build string table of %v, err:%v", lang, err)
+		}
+		stored, err = compress(compression, raw)
+		if err != nil {
+			return
+		}
+		sections = append(sections, stored)
+		rawSizes = append(rawSizes, len(raw))
+	}
+
+	dirSize := 0
+	for _, lang := range languages {
+		if len(lang) > math.MaxUint16 {
+			return fmt.Errorf("language name too long: %v", lang)
+		}
+		dirSize += 2 + len(lang) + 12
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(bundle.Magic)
+	_ = binary.Write(buf, le, bundle.Version)
+	buf.WriteByte(byte(compression))
+	buf.WriteByte(0)
+	_ = binary.Write(buf, le, uint32(len(metaRaw)))
+	_ = binary.Write(buf, le, uint32(len(languages)))
+	buf.Write(metaRaw)
+
+	offset := buf.Len() + dirSize
+	for i, lang := range languages {
+		if uint64(offset+len(sections[i])) > math.MaxUint32 {
+			return fmt.Errorf("bundle too large")
+		}
+		_ = binary.Write(buf, le, uint16(len(lang)))
+		buf.WriteString(lang)
+		_ = binary.Write(buf, le, uint32(offset))
+		_ = binary.Write(buf, le, uint32(len(sections[i])))
+		_ = binary.Write(buf, le, uint32(rawSizes[i]))
+		offset += len(sections[i])
+	}
+	for _, s := range sections {
+		buf.Write(s)
+	}
+
+	_, err = buf.WriteTo(w)
+
+	return
+}
+
+func buildStringTable(kvs map[string]string) ([]byte, error) {
+	le := binary.LittleEndian
+
+	entries := make([]*bundleEntry, 0, len(kvs))
+	for k, v := range kvs {
+		entries = append(entries, &bundleEntry{
+			hash:  bundle.Hash(k),
+			key:   k,
+			value: v,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].hash != entries[j].hash {
+			return entries[i].hash < entries[j].hash
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	index := &bytes.Buffer{}
+	blob := &bytes.Buffer{}
+	_ = binary.Write(index, le, uint32(len(entries)))
+	for _, e := range entries {
+		if uint64(blob.Len()+len(e.key)+len(e.value)) > math.MaxUint32 {
+			return nil, fmt.Errorf("string table too large")
+		}
+		_ = binary.Write(index, le, e.hash)
+		_ = binary.Write(index, le, uint32(blob.Len()))
+		_ = binary.Write(index, le, uint32(len(e.key)))
+		blob.WriteString(e.key)
+		_ = binary.Write(index, le, uint32(blob.Len()))
+		_ = binary.Write(index, le, uint32(len(e.value)))
+		blob.WriteString(e.value)
+	}
+	index.Write(blob.Bytes())
+
+	return index.Bytes(), nil
+}
+
+func compress(c bundle.Compression, raw []byte) (out []byte, err error) {
+	switch c {
+	case bundle.CompressionNone:
+		out = raw
+	case bundle.CompressionDeflate:
+		buf := &bytes.Buffer{}
+		var fw *flate.Writer
+		fw, err = flate.NewWriter(buf, flate.BestCompression)
+		if err != nil {
+			return
+		}
+		if _, err = fw.Write(raw); err != nil {
+			return
+		}
+		if err = fw.Close(); err != nil {
+			return
+		}
+		out = buf.Bytes()
+	case bundle.CompressionZstd:
+		var enc *zstd.Encoder
+		enc, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return
+		}
+		out = enc.EncodeAll(raw, nil)
+		err = enc.Close()
+	default:
+		err = fmt.Errorf("unsupported bundle %v", c)
+	}
+	return
+}
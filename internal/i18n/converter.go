@@ -30,6 +30,8 @@ import (
 	"time"
 
 	"github.com/master-g/i18n/internal/buildinfo"
+	"github.com/master-g/i18n/pkg/bundle"
+	"github.com/master-g/i18n/pkg/locale"
 	"github.com/master-g/i18n/pkg/wkio"
 	"github.com/sirupsen/logrus"
 )
@@ -102,6 +104,41 @@ func (converter *Converter) ReadAppendFile(path string) (err error) {
 
 // Convert csv files
 func (converter *Converter) Convert(csvFiles map[string]string) (err error) {
+	err = converter.Load(csvFiles)
+	if err != nil {
+		return
+	}
+
+	if !converter.cfg.HasMeta {
+		converter.Meta = nil
+	}
+	var resultJson []byte
+	var outFile *os.File
+	resultJson, err = json.Marshal(converter)
+	if err != nil {
+		return
+	}
+	outFile, err = os.Create(converter.cfg.OutputJSONPath)
+	if err != nil {
+		return
+	}
+	defer wkio.SafeClose(outFile)
+	_, err = outFile.Write(resultJson)
+
+	return
+}
+
+// WriteBundle writes the loaded strings as a binary bundle
+func (converter *Converter) WriteBundle(w io.Writer, compression bundle.Compression) error {
+	meta := converter.Meta
+	if !converter.cfg.HasMeta {
+		meta = nil
+	}
+	return WriteBundle(w, meta, converter.Strings, compression)
+}
+
+// Load csv files into the converter
+func (converter *Converter) Load(csvFiles map[string]string) (err error) {
 	// map to avoid source file duplication when append to old json file
 	sourceFiles := make(map[string]string)
 	for _, s := range converter.Meta.SourceFiles {
@@ -120,6 +157,9 @@ func (converter *Converter) Convert(csvFiles map[string]string) (err error) {
 		// read csv file
 		var csvFile *os.File
 		csvFile, err = os.Open(absPath)
+		if err != nil {
+			return
+		}
 		csvReader := csv.NewReader(bufio.NewReader(csvFile))
 
 		// index to language
@@ -131,12 +171,13 @@ func (converter *Converter) Convert(csvFiles map[string]string) (err error) {
 			if err == io.EOF {
 				break
 			} else if err != nil {
+				wkio.SafeClose(csvFile)
 				return
 			}
 			if len(index2language) == 0 {
 				for i, lang := range records {
 					if i > 0 && lang != "" {
-						index2language[i] = lang
+						index2language[i] = locale.Normalize(lang)
 					}
 				}
 			} else {
@@ -162,22 +203,8 @@ func (converter *Converter) Convert(csvFiles map[string]string) (err error) {
 				}
 			}
 		}
+		wkio.SafeClose(csvFile)
 	}
-	if !converter.cfg.HasMeta {
-		converter.Meta = nil
-	}
-	var resultJson []byte
-	var outFile *os.File
-	resultJson, err = json.Marshal(converter)
-	if err != nil {
-		return
-	}
-	outFile, err = os.Create(converter.cfg.OutputJSONPath)
-	if err != nil {
-		return
-	}
-	defer wkio.SafeClose(outFile)
-	_, err = outFile.Write(resultJson)
 
-	return
+	return nil
 }
@@ -0,0 +1,314 @@
+// Package journal records the files changed by each run, along with their
+// content before and after, so a run can be undone
+package journal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/master-g/i18n/pkg/wkfs"
+)
+
+// DefaultDir is the journal folder, relative to the project root, see Locate
+const DefaultDir = ".i18n/journal"
+
+// projectMarkers tell the root of a project
+var projectMarkers = []string{"settings.gradle", "settings.gradle.kts", ".git"}
+
+// Locate returns the journal folder of the project holding start: the
+// nearest DefaultDir walking up from start, or DefaultDir in the nearest
+// folder holding a gradle settings file or a .git folder. DefaultDir in start
+// is returned if there is neither, so runs and undo find the same journal
+// from anywhere in the project
+func Locate(start string) (string, error) {
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for dir := start; ; {
+		if wkfs.IsDir(filepath.Join(dir, DefaultDir)) {
+			return filepath.Join(dir, DefaultDir), nil
+		}
+		for _, marker := range projectMarkers {
+			if _, err = os.Stat(filepath.Join(dir, marker)); err == nil {
+				return filepath.Join(dir, DefaultDir), nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return filepath.Join(start, DefaultDir), nil
+}
+
+const runFile = "run.json"
+
+// File is a file changed by a run
+type File struct {
+	// ID names the saved contents in the run folder
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	// Existed is false for files created by the run
+	Existed bool   `json:"existed"`
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after"`
+}
+
+// Run is a journal entry
+type Run struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Files   []*File   `json:"files"`
+	Undone  bool      `json:"undone,omitempty"`
+	dir     string
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func newID(t time.Time) string {
+	return fmt.Sprintf("%v-%03d", t.Format("20060102-150405"), t.Nanosecond()/int(time.Millisecond))
+}
+
+// Write writes files at once, see wkfs.WriteFiles, and records them as a new
+// run in the journal at dir. If some files are written before a failure, the
+// run recording them is returned along with the error
+func Write(dir string, files map[string][]byte, command string) (run *Run, err error) {
+	now := time.Now()
+	run = &Run{
+		ID:      newID(now),
+		Time:    now,
+		Command: command,
+	}
+	run.dir = filepath.Join(dir, run.ID)
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	contents := make(map[string][]byte)
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		f := &File{ID: fmt.Sprintf("%03d", i), Path: abs, After: checksum(files[path])}
+		before, err := ioutil.ReadFile(path)
+		if err == nil {
+			if bytes.Equal(before, files[path]) {
+				continue
+			}
+			f.Existed = true
+			f.Before = checksum(before)
+			contents[filepath.Join(run.dir, f.ID+".before")] = before
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		contents[filepath.Join(run.dir, f.ID+".after")] = files[path]
+		run.Files = append(run.Files, f)
+	}
+	if len(run.Files) == 0 {
+		return run, nil
+	}
+
+	// the journal goes first, a run interrupted while writing can be undone
+	raw, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	contents[filepath.Join(run.dir, runFile)] = append(raw, '\n')
+	err = wkfs.WriteFiles(contents)
+	if err != nil {
+		return nil, fmt.Errorf("cannot write journal, err:%v", err)
+	}
+
+	err = writeFiles(files)
+	if err != nil {
+		return run.partial(err)
+	}
+	return run, nil
+}
+
+// writeFiles is replaced by tests to fail in the middle of a run
+var writeFiles = wkfs.WriteFiles
+
+// partial handles a run failed with err while renaming its files into place.
+// The journal is removed if no file was written, otherwise it is narrowed
+// to the files written, so the run can still be undone, and returned with
+// err
+func (r *Run) partial(err error) (*Run, error) {
+	var applied []*File
+	for _, f := range r.Files {
+		current, readErr := ioutil.ReadFile(f.Path)
+		if readErr == nil && checksum(current) == f.After {
+			applied = append(applied, f)
+		}
+	}
+	if len(applied) == 0 {
+		_ = os.RemoveAll(r.dir)
+		return nil, err
+	}
+
+	r.Files = applied
+	raw, jsonErr := json.MarshalIndent(r, "", "  ")
+	if jsonErr == nil {
+		jsonErr = ioutil.WriteFile(filepath.Join(r.dir, runFile), append(raw, '\n'), 0644)
+	}
+	if jsonErr != nil {
+		return r, fmt.Errorf("%v, cannot update journal, err:%v", err, jsonErr)
+	}
+	return r, err
+}
+
+// List returns the runs of the journal at dir, oldest first
+func List(dir string) (runs []*Run, err error) {
+	var entries []os.FileInfo
+	entries, err = ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+	for _, v := range entries {
+		if !v.IsDir() {
+			continue
+		}
+		var run *Run
+		run, err = load(filepath.Join(dir, v.Name()))
+		if err != nil {
+			return
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].ID < runs[j].ID
+	})
+	return
+}
+
+// Find returns a run by id, or the latest run not undone yet if id is empty
+func Find(dir, id string) (*Run, error) {
+	if id != "" {
+		if strings.ContainsAny(id, `/\`) {
+			return nil, fmt.Errorf("invalid run id %v", id)
+		}
+		run, err := load(filepath.Join(dir, id))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run %v not found in %v", id, dir)
+		}
+		return run, err
+	}
+	runs, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if !runs[i].Undone {
+			return runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no run to undo in %v", dir)
+}
+
+func load(dir string) (*Run, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(dir, runFile))
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{dir: dir}
+	err = json.Unmarshal(raw, run)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %v, err:%v", filepath.Join(dir, runFile), err)
+	}
+	return run, nil
+}
+
+// Modified returns the files of the run edited since, or already undone
+func (r *Run) Modified() (modified []string, err error) {
+	for _, f := range r.Files {
+		var current []byte
+		current, err = ioutil.ReadFile(f.Path)
+		if os.IsNotExist(err) {
+			err = nil
+			modified = append(modified, f.Path)
+			continue
+		} else if err != nil {
+			return
+		}
+		if checksum(current) != f.After {
+			modified = append(modified, f.Path)
+		}
+	}
+	return
+}
+
+// Undo restores the files of the run to their content before it, files
+// created by the run are removed. Files edited since the run are checked
+// first unless force is true
+func (r *Run) Undo(force bool) error {
+	if r.Undone {
+		return fmt.Errorf("run %v is undone already", r.ID)
+	}
+	if !force {
+		modified, err := r.Modified()
+		if err != nil {
+			return err
+		}
+		if len(modified) > 0 {
+			return fmt.Errorf("%d file(s) changed since run %v: %v", len(modified), r.ID, strings.Join(modified, ", "))
+		}
+	}
+
+	restored := make(map[string][]byte)
+	var removed []string
+	for _, f := range r.Files {
+		if !f.Existed {
+			removed = append(removed, f.Path)
+			continue
+		}
+		before, err := ioutil.ReadFile(filepath.Join(r.dir, f.ID+".before"))
+		if err != nil {
+			return fmt.Errorf("cannot read journal of %v, err:%v", f.Path, err)
+		}
+		if checksum(before) != f.Before {
+			return fmt.Errorf("journal of %v is corrupted", f.Path)
+		}
+		restored[f.Path] = before
+	}
+
+	err := wkfs.WriteFiles(restored)
+	if err != nil {
+		return err
+	}
+	for _, path := range removed {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// folders created for the file, fails if not empty
+		_ = os.Remove(filepath.Dir(path))
+	}
+
+	r.Undone = true
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(r.dir, runFile), append(raw, '\n'), 0644)
+}
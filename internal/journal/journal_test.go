@@ -0,0 +1,234 @@
+package journal
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteAndUndo(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "journal")
+	changed := filepath.Join(root, "values", "strings.xml")
+	same := filepath.Join(root, "values", "same.xml")
+	created := filepath.Join(root, "values-fr", "strings.xml")
+	_ = os.MkdirAll(filepath.Dir(changed), 0755)
+	writeFile(t, changed, "before")
+	writeFile(t, same, "same")
+
+	run, err := Write(dir, map[string][]byte{
+		changed: []byte("after"),
+		same:    []byte("same"),
+		created: []byte("new"),
+	}, "i18n append")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Files) != 2 {
+		t.Fatalf("run records %d files, want 2 without the unchanged one", len(run.Files))
+	}
+	if got := readFile(t, changed); got != "after" {
+		t.Errorf("%v = %q, want after", changed, got)
+	}
+	if got := readFile(t, created); got != "new" {
+		t.Errorf("%v = %q, want new", created, got)
+	}
+
+	found, err := Find(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != run.ID {
+		t.Fatalf("Find() = %v, want %v", found.ID, run.ID)
+	}
+	err = found.Undo(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, changed); got != "before" {
+		t.Errorf("undone %v = %q, want before", changed, got)
+	}
+	if _, err = os.Stat(filepath.Dir(created)); !os.IsNotExist(err) {
+		t.Errorf("created folder %v kept by undo", filepath.Dir(created))
+	}
+	if _, err = Find(dir, ""); err == nil {
+		t.Error("Find() returns an undone run")
+	}
+	if err = found.Undo(true); err == nil {
+		t.Error("Undo() of an undone run succeeds")
+	}
+}
+
+func TestUndoModified(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "journal")
+	path := filepath.Join(root, "strings.xml")
+	writeFile(t, path, "before")
+
+	run, err := Write(dir, map[string][]byte{path: []byte("after")}, "i18n append")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "edited")
+	if err = run.Undo(false); err == nil {
+		t.Fatal("Undo() overwrites a file edited since the run")
+	}
+	if got := readFile(t, path); got != "edited" {
+		t.Errorf("%v = %q, want edited", path, got)
+	}
+	if err = run.Undo(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "before" {
+		t.Errorf("%v = %q, want before", path, got)
+	}
+}
+
+// failAfter writes the first n files in path order then fails
+func failAfter(n int) func(map[string][]byte) error {
+	return func(files map[string][]byte) error {
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths[:n] {
+			err := ioutil.WriteFile(path, files[path], 0644)
+			if err != nil {
+				return err
+			}
+		}
+		return errors.New("rename failed")
+	}
+}
+
+func TestWriteFailure(t *testing.T) {
+	defer func(f func(map[string][]byte) error) { writeFiles = f }(writeFiles)
+
+	tests := []struct {
+		name    string
+		written int
+		// files want recorded by the returned run, none for no run
+		recorded int
+	}{
+		{name: "nothing written", written: 0, recorded: 0},
+		{name: "failure mid-commit", written: 1, recorded: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "journal")
+			a := filepath.Join(root, "a.xml")
+			b := filepath.Join(root, "b.xml")
+			writeFile(t, a, "a")
+			writeFile(t, b, "b")
+
+			writeFiles = failAfter(tt.written)
+			run, err := Write(dir, map[string][]byte{
+				a: []byte("a2"),
+				b: []byte("b2"),
+			}, "i18n append")
+			if err == nil {
+				t.Fatal("Write() succeeds")
+			}
+
+			if tt.recorded == 0 {
+				if run != nil {
+					t.Errorf("Write() returns run %v with nothing written", run.ID)
+				}
+				if runs, _ := List(dir); len(runs) != 0 {
+					t.Errorf("journal keeps %d run(s) with nothing written", len(runs))
+				}
+				return
+			}
+
+			if run == nil {
+				t.Fatal("Write() returns no run for a partial write")
+			}
+			found, err := Find(dir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found.Files) != tt.recorded {
+				t.Fatalf("journal records %d file(s), want %d", len(found.Files), tt.recorded)
+			}
+			err = found.Undo(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readFile(t, a); got != "a" {
+				t.Errorf("undone %v = %q, want a", a, got)
+			}
+			if got := readFile(t, b); got != "b" {
+				t.Errorf("%v = %q, want b", b, got)
+			}
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	res := filepath.Join(project, "app", "src", "main", "res")
+	other := filepath.Join(root, "other", "res")
+	for _, dir := range []string{res, other} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(project, "settings.gradle.kts"), "")
+
+	tests := []struct {
+		name  string
+		start string
+		want  string
+	}{
+		{name: "project root", start: project, want: filepath.Join(project, DefaultDir)},
+		{name: "res directory", start: res, want: filepath.Join(project, DefaultDir)},
+		{name: "no project", start: other, want: filepath.Join(other, DefaultDir)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Locate(tt.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Locate(%v) = %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+
+	// a journal written from the res directory is found from anywhere in the
+	// project, the nearest journal wins over project markers
+	nested := filepath.Join(project, "app", DefaultDir)
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Locate(res); err != nil || got != nested {
+		t.Errorf("Locate(%v) = %v, err:%v, want %v", res, got, err, nested)
+	}
+	if got, err := Locate(project); err != nil || got != filepath.Join(project, DefaultDir) {
+		t.Errorf("Locate(%v) = %v, err:%v, want the project journal", project, got, err)
+	}
+}
@@ -0,0 +1,381 @@
+// Package keyops renames, deletes, moves and copies string resources across
+// every values folder of an android res directory
+package keyops
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+	"github.com/master-g/i18n/pkg/wkfs"
+)
+
+// Change describes a single edit
+type Change struct {
+	resdir.Location
+	Desc string `json:"desc"`
+}
+
+type resource struct {
+	file *file
+	e    *resxml.Element
+}
+
+type file struct {
+	path   string
+	doc    *resxml.Document
+	format *resxml.Formatter
+}
+
+type folder struct {
+	path  string
+	files []*file
+	// resources by id, nil for resources added in this run
+	resources map[string]*resource
+}
+
+// Workspace holds the parsed values folders of a res directory, edits are
+// kept in memory, see Pending
+type Workspace struct {
+	ResDir  string
+	folders map[string]*folder
+	changes []*Change
+}
+
+// Open loads all xml files of the values folders of a res directory
+func Open(resDir string) (w *Workspace, err error) {
+	var matches []string
+	matches, err = filepath.Glob(filepath.Join(resDir, "values*"))
+	if err != nil {
+		return
+	}
+
+	w = &Workspace{
+		ResDir:  resDir,
+		folders: make(map[string]*folder),
+	}
+	for _, v := range matches {
+		if !wkfs.IsDir(v) {
+			continue
+		}
+		if _, err = resdir.ParseFolder(filepath.Base(v)); err != nil {
+			// not a resource folder
+			err = nil
+			continue
+		}
+		var files []*resdir.File
+		files, err = resdir.LoadFolder(v)
+		if err != nil {
+			return
+		}
+		f := &folder{
+			path:      v,
+			resources: make(map[string]*resource),
+		}
+		for _, loaded := range files {
+			rf := &file{path: loaded.Path, doc: loaded.Doc, format: loaded.Doc.Formatter()}
+			f.files = append(f.files, rf)
+			for _, e := range loaded.Doc.Elements() {
+				if !resdir.IsStringResource(e.Tag) {
+					continue
+				}
+				id := resdir.ResourceID(e.Tag, e.Name)
+				if _, ok := f.resources[id]; !ok {
+					f.resources[id] = &resource{file: rf, e: e}
+				}
+			}
+		}
+		w.folders[filepath.Base(v)] = f
+	}
+
+	if len(w.folders) == 0 {
+		err = fmt.Errorf("no values folder found in %v", resDir)
+	}
+
+	return
+}
+
+// Changes returns all edits made so far
+func (w *Workspace) Changes() []*Change {
+	return w.changes
+}
+
+func (w *Workspace) sortedFolders() []*folder {
+	names := make([]string, 0, len(w.folders))
+	for name := range w.folders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	folders := make([]*folder, 0, len(names))
+	for _, name := range names {
+		folders = append(folders, w.folders[name])
+	}
+	return folders
+}
+
+func (w *Workspace) record(f *file, line int, format string, args ...interface{}) {
+	w.changes = append(w.changes, &Change{
+		Location: resdir.Location{File: f.path, Line: line},
+		Desc:     fmt.Sprintf(format, args...),
+	})
+}
+
+// lookup returns the resources named key in a folder, by tag
+func (f *folder) lookup(key string) (found map[string]*resource, err error) {
+	for _, tag := range []string{resxml.TagString, resxml.TagPlurals, resxml.TagStringArray} {
+		r, ok := f.resources[resdir.ResourceID(tag, key)]
+		if !ok {
+			continue
+		}
+		if r == nil {
+			return nil, fmt.Errorf("%v was added or renamed in this run and cannot be edited again", key)
+		}
+		if found == nil {
+			found = make(map[string]*resource)
+		}
+		found[tag] = r
+	}
+	return
+}
+
+// Tags returns the resource types named key in any folder, e.g. string, plurals
+func (w *Workspace) Tags(key string) []string {
+	set := make(map[string]bool)
+	for _, f := range w.folders {
+		for _, tag := range []string{resxml.TagString, resxml.TagPlurals, resxml.TagStringArray} {
+			if _, ok := f.resources[resdir.ResourceID(tag, key)]; ok {
+				set[tag] = true
+			}
+		}
+	}
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// renamed returns the element text with a new name
+func renamed(r *resource, name string) string {
+	startTag := r.file.format.StartTag(r.e.Tag, name, r.e.Attrs)
+	if r.e.SelfClosing() {
+		return strings.TrimSuffix(startTag, ">") + "/>"
+	}
+	return startTag + string(r.file.doc.Raw()[r.e.InnerStart:r.e.End])
+}
+
+// Rename renames a key in every values folder
+func (w *Workspace) Rename(old, newer string) error {
+	if err := w.checkTarget(old, newer); err != nil {
+		return err
+	}
+	return w.each(old, func(f *folder, tag string, r *resource) error {
+		id := resdir.ResourceID(tag, newer)
+		startTag := r.file.format.StartTag(tag, newer, r.e.Attrs)
+		r.file.doc.ReplaceStartTag(r.e, startTag)
+		delete(f.resources, resdir.ResourceID(tag, old))
+		// the start tag is rewritten already
+		f.resources[id] = nil
+		w.record(r.file, r.e.Line, "rename %v/%v to %v", tag, old, newer)
+		return nil
+	})
+}
+
+// Delete deletes a key from every values folder
+func (w *Workspace) Delete(key string) error {
+	return w.each(key, func(f *folder, tag string, r *resource) error {
+		r.file.doc.Remove(r.e)
+		delete(f.resources, resdir.ResourceID(tag, key))
+		w.record(r.file, r.e.Line, "delete %v/%v", tag, key)
+		return nil
+	})
+}
+
+// Copy copies a key to a new name right after it, in every values folder
+func (w *Workspace) Copy(old, newer string) error {
+	if err := w.checkTarget(old, newer); err != nil {
+		return err
+	}
+	return w.each(old, func(f *folder, tag string, r *resource) error {
+		r.file.doc.InsertAfter(r.e, renamed(r, newer))
+		f.resources[resdir.ResourceID(tag, newer)] = nil
+		w.record(r.file, r.e.Line, "copy %v/%v to %v", tag, old, newer)
+		return nil
+	})
+}
+
+// checkTarget validates newer as the new name of old before any folder is
+// edited, it fails if newer is taken in any folder by a resource of a type
+// old has, even in folders old is missing from
+func (w *Workspace) checkTarget(old, newer string) error {
+	if err := CheckName(newer); err != nil {
+		return err
+	}
+	// fails on a missing key or one edited in this run already
+	err := w.each(old, func(f *folder, tag string, r *resource) error { return nil })
+	if err != nil {
+		return err
+	}
+	tags := w.Tags(old)
+	for _, f := range w.sortedFolders() {
+		for _, tag := range tags {
+			id := resdir.ResourceID(tag, newer)
+			if _, ok := f.resources[id]; ok {
+				return fmt.Errorf("%v already exists in %v", id, f.path)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckName rejects names aapt cannot compile into an R field: a name starts
+// with a letter or an underscore, followed by letters, digits, underscores or
+// dots, and is not a Java keyword
+func CheckName(name string) error {
+	if name == "" {
+		return errors.New("empty resource name")
+	}
+	for i, c := range name {
+		if unicode.IsLetter(c) || c == '_' || (i > 0 && (unicode.IsDigit(c) || c == '.')) {
+			continue
+		}
+		return fmt.Errorf("invalid resource name %q, unexpected %q at %d, expect letters, digits, '_' or '.' not leading with a digit or '.'", name, c, i)
+	}
+	if javaKeywords[name] {
+		return fmt.Errorf("invalid resource name %q, it is a Java keyword", name)
+	}
+	return nil
+}
+
+var javaKeywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "class": true, "const": true,
+	"continue": true, "default": true, "do": true, "double": true, "else": true,
+	"enum": true, "extends": true, "false": true, "final": true, "finally": true,
+	"float": true, "for": true, "goto": true, "if": true, "implements": true,
+	"import": true, "instanceof": true, "int": true, "interface": true, "long": true,
+	"native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "short": true, "static": true,
+	"strictfp": true, "super": true, "switch": true, "synchronized": true, "this": true,
+	"throw": true, "throws": true, "transient": true, "true": true, "try": true,
+	"void": true, "volatile": true, "while": true,
+}
+
+// MoveTo moves a key to the same values folders and file names of another
+// res directory, the key is kept here if keep is true
+func (w *Workspace) MoveTo(dst *Workspace, key string, keep bool) error {
+	verb := "move"
+	if keep {
+		verb = "copy"
+	}
+	return w.each(key, func(f *folder, tag string, r *resource) error {
+		id := resdir.ResourceID(tag, key)
+		df := dst.folder(filepath.Base(f.path))
+		if _, ok := df.resources[id]; ok {
+			return fmt.Errorf("%v already exists in %v", id, df.path)
+		}
+		target, err := dst.file(df, filepath.Base(r.file.path))
+		if err != nil {
+			return err
+		}
+		target.doc.AppendLines([]string{renamed(r, key)}, false)
+		df.resources[id] = nil
+		dst.record(target, 0, "%v %v/%v from %v", verb, tag, key, r.file.path)
+		if !keep {
+			r.file.doc.Remove(r.e)
+			delete(f.resources, id)
+			w.record(r.file, r.e.Line, "move %v/%v to %v", tag, key, target.path)
+		}
+		return nil
+	})
+}
+
+// each calls fn for every resource named key, it fails if there is none
+func (w *Workspace) each(key string, fn func(f *folder, tag string, r *resource) error) error {
+	found := false
+	for _, f := range w.sortedFolders() {
+		resources, err := f.lookup(key)
+		if err != nil {
+			return err
+		}
+		tags := make([]string, 0, len(resources))
+		for tag := range resources {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			found = true
+			if err = fn(f, tag, resources[tag]); err != nil {
+				return err
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("key %v not found in %v", key, w.ResDir)
+	}
+	return nil
+}
+
+// folder returns a values folder by name, created if missing
+func (w *Workspace) folder(name string) *folder {
+	if f, ok := w.folders[name]; ok {
+		return f
+	}
+	if w.folders == nil {
+		w.folders = make(map[string]*folder)
+	}
+	f := &folder{
+		path:      filepath.Join(w.ResDir, name),
+		resources: make(map[string]*resource),
+	}
+	w.folders[name] = f
+	return f
+}
+
+// file returns a file of a folder by name, created from the skeleton if missing
+func (w *Workspace) file(f *folder, name string) (*file, error) {
+	for _, v := range f.files {
+		if filepath.Base(v.path) == name {
+			return v, nil
+		}
+	}
+	path := filepath.Join(f.path, name)
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(strings.TrimSpace(string(raw))) == 0) {
+		raw = []byte(resxml.Skeleton)
+	} else if err != nil {
+		return nil, err
+	}
+	doc, err := resxml.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %v, err:%v", path, err)
+	}
+	v := &file{path: path, doc: doc, format: doc.Formatter()}
+	f.files = append(f.files, v)
+	return v, nil
+}
+
+// Pending returns the content of every file changed so far
+func (w *Workspace) Pending() (pending map[string][]byte, err error) {
+	pending = make(map[string][]byte)
+	for _, f := range w.folders {
+		for _, v := range f.files {
+			if !v.doc.Changed() {
+				continue
+			}
+			pending[v.path], err = v.doc.Bytes()
+			if err != nil {
+				return nil, fmt.Errorf("cannot edit %v, err:%v", v.path, err)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,285 @@
+package keyops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree writes files under root, by slash separated relative path
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckRenames(t *testing.T) {
+	tests := []struct {
+		name    string
+		renames []*Rename
+		wantErr string
+	}{
+		{
+			name:    "independent",
+			renames: []*Rename{{Old: "a", New: "b"}, {Old: "c", New: "d"}},
+		},
+		{
+			name:    "single",
+			renames: []*Rename{{Old: "a", New: "x"}},
+		},
+		{
+			name:    "invalid new name",
+			renames: []*Rename{{Old: "a", New: "1a"}},
+			wantErr: "invalid resource name",
+		},
+		{
+			name:    "to itself",
+			renames: []*Rename{{Old: "a", New: "a"}},
+			wantErr: "renamed to itself",
+		},
+		{
+			name:    "renamed twice",
+			renames: []*Rename{{Old: "a", New: "b"}, {Old: "a", New: "c"}},
+			wantErr: "more than once",
+		},
+		{
+			name:    "same new name",
+			renames: []*Rename{{Old: "a", New: "c"}, {Old: "b", New: "c"}},
+			wantErr: "both renamed to c",
+		},
+		{
+			name:    "chained",
+			renames: []*Rename{{Old: "a", New: "b"}, {Old: "b", New: "c"}},
+			wantErr: "rename a to c directly",
+		},
+		{
+			name:    "chained in reverse order",
+			renames: []*Rename{{Old: "b", New: "c"}, {Old: "a", New: "b"}},
+			wantErr: "rename a to c directly",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRenames(tt.renames)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckRenames() err:%v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckRenames() err:%v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "title", valid: true},
+		{name: "_title", valid: true},
+		{name: "Title2", valid: true},
+		{name: "settings.title", valid: true},
+		{name: "título", valid: true},
+		{name: ""},
+		{name: "2title"},
+		{name: ".title"},
+		{name: "title-long"},
+		{name: "title long"},
+		{name: "title/long"},
+		{name: "class"},
+		{name: "new"},
+	}
+	for _, tt := range tests {
+		if err := CheckName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("CheckName(%q) err:%v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestTargetConflict(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"values/strings.xml":    "<resources>\n    <string name=\"title\">Title</string>\n</resources>\n",
+		"values-de/strings.xml": "<resources>\n    <string name=\"title\">Titel</string>\n</resources>\n",
+		"values-fr/strings.xml": "<resources>\n    <string name=\"heading\">Titre</string>\n</resources>\n",
+	})
+	ops := map[string]func(w *Workspace, old, newer string) error{
+		"rename": (*Workspace).Rename,
+		"copy":   (*Workspace).Copy,
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			w, err := Open(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = op(w, "title", "heading")
+			if err == nil || !strings.Contains(err.Error(), "values-fr") {
+				t.Errorf("%v onto a key of a folder without the old key err:%v", name, err)
+			}
+			err = op(w, "title", "2title")
+			if err == nil || !strings.Contains(err.Error(), "invalid resource name") {
+				t.Errorf("%v to an invalid name err:%v", name, err)
+			}
+			pending, err := w.Pending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != 0 || len(w.Changes()) != 0 {
+				t.Errorf("failed %v left %d pending files and %d changes", name, len(pending), len(w.Changes()))
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"values/strings.xml":    "<resources>\n    <string name=\"title\" translatable=\"false\">Title</string>\n    <plurals name=\"title\">\n        <item quantity=\"other\">Titles</item>\n    </plurals>\n</resources>\n",
+		"values-fr/strings.xml": "<resources>\n    <string name=\"title\">Titre</string>\n    <string name=\"other\">Autre</string>\n</resources>\n",
+	})
+
+	w, err := Open(filepath.Join(root, "res-missing"))
+	if err == nil {
+		t.Fatal("Open() of a folder without values succeeds")
+	}
+	w, err = Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := w.Tags("title"); strings.Join(tags, ",") != "plurals,string" {
+		t.Errorf("Tags(title) = %v, want plurals and string", tags)
+	}
+	if err = w.Rename("missing", "x"); err == nil {
+		t.Error("Rename() of a missing key succeeds")
+	}
+	if err = w.Rename("title", "other"); err == nil {
+		t.Error("Rename() onto an existing key succeeds")
+	}
+
+	w, err = Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Rename("title", "heading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := w.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("Pending() has %d files, want 2", len(pending))
+	}
+	want := map[string]string{
+		"values/strings.xml":    "<resources>\n    <string name=\"heading\" translatable=\"false\">Title</string>\n    <plurals name=\"heading\">\n        <item quantity=\"other\">Titles</item>\n    </plurals>\n</resources>\n",
+		"values-fr/strings.xml": "<resources>\n    <string name=\"heading\">Titre</string>\n    <string name=\"other\">Autre</string>\n</resources>\n",
+	}
+	for name, content := range want {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if got := string(pending[path]); got != content {
+			t.Errorf("%v =\n%v\nwant\n%v", name, got, content)
+		}
+	}
+	if len(w.Changes()) != 3 {
+		t.Errorf("Changes() has %d changes, want 3", len(w.Changes()))
+	}
+}
+
+func TestRewriteReferences(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"src/main/res/values/strings.xml": "<resources>\n    <string name=\"title\">Title</string>\n    <string name=\"label\">@string/title</string>\n</resources>\n",
+		"src/main/java/Main.kt":           "val a = R.string.title\nval b = R.string.title_long\nval c = R.plurals.title\n",
+		"src/main/res/layout/main.xml":    "<TextView android:text=\"@string/title\" />\n",
+		"build/generated/R.java":          "R.string.title\n",
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	err = os.Chdir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		res  string
+		refs string
+	}{
+		{name: "same form", res: "src/main/res", refs: "src"},
+		{name: "dot prefix", res: "./src/main/res", refs: "src"},
+		{name: "absolute refs", res: "src/main/res", refs: filepath.Join(root, "src")},
+		{name: "absolute res", res: filepath.Join(root, "src", "main", "res"), refs: "./src/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Open(tt.res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			renames := []*Rename{{Old: "title", New: "heading"}}
+			for _, r := range renames {
+				r.Tags = w.Tags(r.Old)
+				if err = w.Rename(r.Old, r.New); err != nil {
+					t.Fatal(err)
+				}
+			}
+			pending, err := w.Pending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			changes, err := RewriteReferences(tt.refs, renames, pending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != 3 {
+				t.Errorf("RewriteReferences() made %d changes, want 3", len(changes))
+			}
+
+			got := make(map[string]string, len(pending))
+			for path, content := range pending {
+				abs, err := absPath(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rel, err := filepath.Rel(root, abs)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[filepath.ToSlash(rel)] = string(content)
+			}
+			want := map[string]string{
+				"src/main/res/values/strings.xml": "<resources>\n    <string name=\"heading\">Title</string>\n    <string name=\"label\">@string/heading</string>\n</resources>\n",
+				"src/main/java/Main.kt":           "val a = R.string.heading\nval b = R.string.title_long\nval c = R.plurals.title\n",
+				"src/main/res/layout/main.xml":    "<TextView android:text=\"@string/heading\" />\n",
+			}
+			if len(got) != len(want) {
+				t.Errorf("pending has %d files, want %d: %v", len(got), len(want), got)
+			}
+			for name, content := range want {
+				if got[name] != content {
+					t.Errorf("%v =\n%v\nwant\n%v", name, got[name], content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package keyops
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/master-g/i18n/internal/resdir"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// folders never holding sources
+var skippedDirs = map[string]bool{
+	"build":        true,
+	".git":         true,
+	".gradle":      true,
+	".idea":        true,
+	"node_modules": true,
+}
+
+// source files references are rewritten in
+var referenceTypes = map[string]bool{
+	".kt":   true,
+	".java": true,
+	".xml":  true,
+}
+
+// refType returns the R class type of a resource tag
+func refType(tag string) string {
+	switch tag {
+	case resxml.TagPlurals:
+		return "plurals"
+	case resxml.TagStringArray:
+		return "array"
+	}
+	return "string"
+}
+
+// Rename is a key rename along with the resource types it applies to
+type Rename struct {
+	Old  string
+	New  string
+	Tags []string
+}
+
+// CheckRenames rejects invalid new names and renames that chain or collide:
+// a key renamed twice, two keys renamed to the same name, or a new name
+// renamed again like a to b then b to c
+func CheckRenames(renames []*Rename) error {
+	olds := make(map[string]bool, len(renames))
+	news := make(map[string]string, len(renames))
+	for _, r := range renames {
+		if r.Old == r.New {
+			return fmt.Errorf("%v is renamed to itself", r.Old)
+		}
+		if err := CheckName(r.New); err != nil {
+			return err
+		}
+		if olds[r.Old] {
+			return fmt.Errorf("%v is renamed more than once", r.Old)
+		}
+		olds[r.Old] = true
+		if old, ok := news[r.New]; ok {
+			return fmt.Errorf("%v and %v are both renamed to %v", old, r.Old, r.New)
+		}
+		news[r.New] = r.Old
+	}
+	for _, r := range renames {
+		if old, ok := news[r.Old]; ok {
+			return fmt.Errorf("%v is renamed to %v and then to %v, rename %v to %v directly", old, r.Old, r.New, old, r.New)
+		}
+	}
+	return nil
+}
+
+func referenceRegex(tag, name string) *regexp.Regexp {
+	t := refType(tag)
+	return regexp.MustCompile(`(\bR\.` + t + `\.|@` + t + `/)` + regexp.QuoteMeta(name) + `\b`)
+}
+
+// absPath returns the absolute and clean form of path, to match a file given
+// in different forms like res/values/strings.xml and ./res/values/strings.xml
+func absPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(abs), nil
+}
+
+// RewriteReferences rewrites R.string.old and @string/old references of
+// renamed keys in the Kotlin, Java and xml files under root. Files in pending
+// are rewritten from their pending content, which is updated in place, paths
+// of pending and root may be in different forms
+func RewriteReferences(root string, renames []*Rename, pending map[string][]byte) (changes []*Change, err error) {
+	type replacement struct {
+		re   *regexp.Regexp
+		repl string
+		desc string
+	}
+	var replacements []*replacement
+	for _, r := range renames {
+		for _, tag := range r.Tags {
+			replacements = append(replacements, &replacement{
+				re:   referenceRegex(tag, r.Old),
+				repl: "${1}" + r.New,
+				desc: fmt.Sprintf("reference %v/%v to %v", refType(tag), r.Old, r.New),
+			})
+		}
+	}
+	if len(replacements) == 0 {
+		return
+	}
+
+	// pending paths by absolute path
+	pendingPaths := make(map[string]string, len(pending))
+	for path := range pending {
+		var abs string
+		abs, err = absPath(path)
+		if err != nil {
+			return
+		}
+		pendingPaths[abs] = path
+	}
+
+	err = walkSources(root, func(path string) error {
+		abs, err := absPath(path)
+		if err != nil {
+			return err
+		}
+		key, ok := pendingPaths[abs]
+		if !ok {
+			key = path
+		}
+		content, ok := pending[key]
+		if !ok {
+			content, err = ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+		}
+		changed := false
+		for _, r := range replacements {
+			for _, loc := range r.re.FindAllIndex(content, -1) {
+				changes = append(changes, &Change{
+					Location: resdir.Location{File: path, Line: lineAt(content, loc[0])},
+					Desc:     r.desc,
+				})
+				changed = true
+			}
+			if changed {
+				content = r.re.ReplaceAll(content, []byte(r.repl))
+			}
+		}
+		if changed {
+			pending[key] = content
+		}
+		return nil
+	})
+
+	return
+}
+
+// FindReferences returns the references to keys under root, e.g. to warn
+// about keys being deleted
+func FindReferences(root string, keys map[string][]string) (found []*Change, err error) {
+	err = walkSources(root, func(path string) error {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, tag := range keys[name] {
+				for _, loc := range referenceRegex(tag, name).FindAllIndex(content, -1) {
+					found = append(found, &Change{
+						Location: resdir.Location{File: path, Line: lineAt(content, loc[0])},
+						Desc:     fmt.Sprintf("reference to %v/%v", refType(tag), name),
+					})
+				}
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func walkSources(root string, fn func(path string) error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !referenceTypes[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+func lineAt(content []byte, offset int) int {
+	return strings.Count(string(content[:offset]), "\n") + 1
+}
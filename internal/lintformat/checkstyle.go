@@ -0,0 +1,46 @@
+package lintformat
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+type checkstyle struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func writeCheckstyle(w io.Writer, tool *Tool, results []*model.LintResult) error {
+	doc := &checkstyle{Version: "4.3"}
+	files, grouped := byFile(tool, results)
+	for _, file := range files {
+		f := &checkstyleFile{Name: file}
+		for _, r := range grouped[file] {
+			f.Errors = append(f.Errors, &checkstyleError{
+				Line:     r.Line,
+				Column:   r.Column,
+				Severity: string(r.Severity),
+				Message:  r.Desc,
+				Source:   tool.source(r.Rule),
+			})
+		}
+		doc.Files = append(doc.Files, f)
+	}
+	return writeXML(w, doc)
+}
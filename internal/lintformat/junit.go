@@ -0,0 +1,92 @@
+package lintformat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes a test suite per file and a failed test case per result
+func writeJUnit(w io.Writer, tool *Tool, results []*model.LintResult) error {
+	suites := &junitTestSuites{Name: tool.Name, Suites: []*junitTestSuite{}}
+	files, grouped := byFile(tool, results)
+	for _, file := range files {
+		name := file
+		if name == "" {
+			name = tool.Name
+		}
+		suite := &junitTestSuite{Name: name}
+		for _, r := range grouped[file] {
+			suite.Cases = append(suite.Cases, &junitTestCase{
+				Name:      junitCaseName(r),
+				ClassName: name,
+				File:      file,
+				Line:      r.Line,
+				Failure: &junitFailure{
+					Message: r.Desc,
+					Type:    string(r.Severity),
+					Text:    junitText(name, r),
+				},
+			})
+		}
+		suite.Tests = len(suite.Cases)
+		suite.Failures = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return writeXML(w, suites)
+}
+
+// junitCaseName names the test case of a result like "rule: key (lang)"
+func junitCaseName(r *model.LintResult) string {
+	name := r.Key
+	if r.Rule != "" {
+		name = r.Rule + ": " + name
+	}
+	if r.Language != "" {
+		name += " (" + r.Language + ")"
+	}
+	return name
+}
+
+// junitText describes a result like "file:line:column: severity: desc [rule]"
+func junitText(file string, r *model.LintResult) string {
+	text := fmt.Sprintf("%v: %v: %v", position(file, r), r.Severity, r.Desc)
+	if r.Rule != "" {
+		text += " [" + r.Rule + "]"
+	}
+	return text
+}
@@ -0,0 +1,139 @@
+// Package lintformat writes lint results in formats of code review and CI
+// systems: SARIF for code scanning, JUnit XML and Checkstyle for Jenkins
+package lintformat
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+// formats of lint results
+const (
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"
+	FormatJUnit      = "junit"
+	FormatCheckstyle = "checkstyle"
+)
+
+// Formats are the supported formats
+var Formats = []string{FormatSARIF, FormatJUnit, FormatCheckstyle, FormatJSON}
+
+// Valid reports whether format is supported
+func Valid(format string) bool {
+	for _, v := range Formats {
+		if v == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Tool describes the program reporting results
+type Tool struct {
+	Name    string
+	Version string
+	URI     string
+	// BaseDir is the directory file paths are relative to
+	BaseDir string
+}
+
+// path returns the slash separated path of file relative to the base dir
+func (t *Tool) path(file string) string {
+	if file == "" {
+		return ""
+	}
+	if t.BaseDir != "" {
+		if rel, err := filepath.Rel(t.BaseDir, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// source returns the checker name of rule, results of checks without a rule
+// are reported by the tool itself
+func (t *Tool) source(rule string) string {
+	if rule == "" {
+		return t.Name
+	}
+	return t.Name + "." + rule
+}
+
+// position returns file:line:column of a result, as far as it is known
+func position(file string, r *model.LintResult) string {
+	switch {
+	case r.Line == 0:
+		return file
+	case r.Column == 0:
+		return fmt.Sprintf("%v:%d", file, r.Line)
+	}
+	return fmt.Sprintf("%v:%d:%d", file, r.Line, r.Column)
+}
+
+// Write writes results in format to w
+func Write(w io.Writer, format string, tool *Tool, results []*model.LintResult) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, tool, results)
+	case FormatSARIF:
+		return writeSARIF(w, tool, results)
+	case FormatJUnit:
+		return writeJUnit(w, tool, results)
+	case FormatCheckstyle:
+		return writeCheckstyle(w, tool, results)
+	}
+	return fmt.Errorf("unknown lint format %q", format)
+}
+
+func writeJSON(w io.Writer, tool *Tool, results []*model.LintResult) error {
+	located := make([]*model.LintResult, 0, len(results))
+	for _, r := range results {
+		v := *r
+		v.File = tool.path(r.File)
+		located = append(located, &v)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(located)
+}
+
+// byFile groups results by file, files and their results sorted by position
+func byFile(tool *Tool, results []*model.LintResult) (files []string, grouped map[string][]*model.LintResult) {
+	grouped = make(map[string][]*model.LintResult)
+	for _, r := range results {
+		file := tool.path(r.File)
+		if _, ok := grouped[file]; !ok {
+			files = append(files, file)
+		}
+		grouped[file] = append(grouped[file], r)
+	}
+	sort.Strings(files)
+	for _, v := range grouped {
+		sort.SliceStable(v, func(i, j int) bool {
+			if v[i].Line != v[j].Line {
+				return v[i].Line < v[j].Line
+			}
+			return v[i].Column < v[j].Column
+		})
+	}
+	return
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,81 @@
+package lintformat
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestWrite(t *testing.T) {
+	tool := &Tool{Name: "i18n", Version: "1.0.0", URI: "https://github.com/master-g/i18n", BaseDir: "/repo"}
+	results := []*model.LintResult{
+		{
+			Rule:     "placeholder-missing",
+			Severity: model.SeverityError,
+			Language: "zh",
+			Key:      "greeting",
+			Desc:     `"%1$s" of lang:en is missing in lang:zh`,
+			File:     "/repo/i18n/strings.csv",
+			Line:     3,
+			Column:   9,
+		},
+		{
+			Rule:     "invalid-specifier",
+			Severity: model.SeverityWarning,
+			Language: "en",
+			Key:      "cart & <total>",
+			Desc:     `"%q" is not a valid specifier`,
+			File:     "/repo/i18n/strings.csv",
+			Line:     2,
+			Column:   6,
+		},
+		{
+			Rule:     "placeholder-mixed",
+			Severity: model.SeverityInfo,
+			Language: "ja",
+			Key:      "count",
+			Desc:     "positional and non-positional specifiers mixed in lang:ja",
+			File:     "/repo/i18n/plurals.csv",
+			Line:     5,
+		},
+		{
+			Severity: model.SeverityError,
+			Key:      "reasons",
+			Desc:     "string-array 'reasons' has different lengths",
+		},
+	}
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := Write(buf, format, tool, results)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", format+".golden")
+			if *update {
+				err = ioutil.WriteFile(golden, buf.Bytes(), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("Write(%v) =\n%s\nwant\n%s", format, buf.Bytes(), want)
+			}
+		})
+	}
+
+	if err := Write(&bytes.Buffer{}, "html", tool, results); err == nil {
+		t.Error("Write(html) succeeds")
+	}
+}
@@ -0,0 +1,143 @@
+package lintformat
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+// SARIF 2.1.0, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool      `json:"tool"`
+	ColumnKind string         `json:"columnKind"`
+	Results    []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifText          `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId,omitempty"`
+	RuleIndex *int             `json:"ruleIndex,omitempty"`
+	Level     string           `json:"level"`
+	Message   sarifText        `json:"message"`
+	Locations []*sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevel maps severities to SARIF levels
+func sarifLevel(severity model.Severity) string {
+	switch severity {
+	case model.SeverityError:
+		return "error"
+	case model.SeverityWarning:
+		return "warning"
+	case model.SeverityOff:
+		return "none"
+	default:
+		return "note"
+	}
+}
+
+func writeSARIF(w io.Writer, tool *Tool, results []*model.LintResult) error {
+	driver := sarifDriver{
+		Name:           tool.Name,
+		Version:        tool.Version,
+		InformationURI: tool.URI,
+		Rules:          make([]*sarifRule, 0, len(model.Rules)),
+	}
+	ruleIndex := make(map[string]int, len(model.Rules))
+	for i, rule := range model.Rules {
+		ruleIndex[rule.ID] = i
+		driver.Rules = append(driver.Rules, &sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifText{Text: rule.Desc},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity)},
+		})
+	}
+
+	// cell columns are counted in code points, not in the utf-16 code units
+	// SARIF defaults to
+	run := &sarifRun{
+		Tool:       sarifTool{Driver: driver},
+		ColumnKind: "unicodeCodePoints",
+		Results:    make([]*sarifResult, 0, len(results)),
+	}
+	for _, r := range results {
+		result := &sarifResult{
+			RuleID:  r.Rule,
+			Level:   sarifLevel(r.Severity),
+			Message: sarifText{Text: r.Desc},
+		}
+		// results of checks without a rule, like string arrays, have no index
+		if i, ok := ruleIndex[r.Rule]; ok {
+			result.RuleIndex = &i
+		}
+		if r.File != "" {
+			location := &sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: tool.path(r.File)},
+			}}
+			if r.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: r.Line, StartColumn: r.Column}
+			}
+			result.Locations = append(result.Locations, location)
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []*sarifRun{run}})
+}
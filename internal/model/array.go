@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArrayKey returns the source key of a string array item
+func ArrayKey(name string, index int) string {
+	return fmt.Sprintf("%v[%d]", name, index)
+}
+
+// ArrayIndex parses the selector of a string array item key
+func ArrayIndex(selector string) (int, bool) {
+	if selector == "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(selector)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+// MissingArrayItem returns the first index missing from sorted array item
+// indices, arrays have no gaps and start at 0
+func MissingArrayItem(indices []int) (int, bool) {
+	for i, index := range indices {
+		if i != index {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ExpandArrays splits values of keys like report_reasons[] by delimiter
+// into indexed array items report_reasons[0], report_reasons[1], ...
+func (s *SourceFile) ExpandArrays(delimiter string) {
+	if s == nil || delimiter == "" {
+		return
+	}
+
+	for _, kvs := range s.Languages {
+		for key, value := range kvs.KVS {
+			if !strings.HasSuffix(key, "[]") {
+				continue
+			}
+			name := strings.TrimSuffix(key, "[]")
+			delete(kvs.KVS, key)
+			for i, item := range strings.Split(value, delimiter) {
+				kvs.KVS[ArrayKey(name, i)] = strings.TrimSpace(item)
+			}
+		}
+	}
+}
+
+// CheckArrays reports string arrays with missing items, or with different
+// lengths across languages
+func CheckArrays(data map[string]map[string]string) (result []*LintResult) {
+	// lang -> array name -> indices
+	arrays := make(map[string]map[string][]int)
+	for lang, kvs := range data {
+		for key := range kvs {
+			name, selector := SplitResourceKey(key)
+			index, ok := ArrayIndex(selector)
+			if !ok {
+				continue
+			}
+			if arrays[lang] == nil {
+				arrays[lang] = make(map[string][]int)
+			}
+			arrays[lang][name] = append(arrays[lang][name], index)
+		}
+	}
+
+	languages := make([]string, 0, len(arrays))
+	for lang := range arrays {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	// array name -> length -> languages
+	lengths := make(map[string]map[int][]string)
+	for _, lang := range languages {
+		for name, indices := range arrays[lang] {
+			sort.Ints(indices)
+			if i, ok := MissingArrayItem(indices); ok {
+				result = append(result, &LintResult{
+					Language: lang,
+					Key:      ArrayKey(name, i),
+					Desc:     fmt.Sprintf("item %d of string-array '%v' is missing in lang:%v", i, name, lang),
+				})
+			}
+			length := indices[len(indices)-1] + 1
+			if lengths[name] == nil {
+				lengths[name] = make(map[int][]string)
+			}
+			lengths[name][length] = append(lengths[name][length], lang)
+		}
+	}
+
+	names := make([]string, 0, len(lengths))
+	for name := range lengths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		l2langs := lengths[name]
+		if len(l2langs) < 2 {
+			continue
+		}
+		var desc []string
+		for length, langs := range l2langs {
+			desc = append(desc, fmt.Sprintf("%d in %v", length, strings.Join(langs, ",")))
+		}
+		sort.Strings(desc)
+		result = append(result, &LintResult{
+			Key:  name,
+			Desc: fmt.Sprintf("string-array '%v' has different lengths across languages: %v", name, strings.Join(desc, "; ")),
+		})
+	}
+
+	return
+}
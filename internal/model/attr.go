@@ -0,0 +1,43 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// attributes of android string resources that can be set from source columns
+const (
+	AttrTranslatable = "translatable"
+	AttrFormatted    = "formatted"
+	AttrProduct      = "product"
+)
+
+const (
+	attrColumnPrefix  = "attr:"
+	toolsColumnPrefix = "tools:"
+)
+
+// AttributeColumn reports whether a source header is an attribute column,
+// and returns the attribute name. Attribute columns are translatable,
+// formatted, product, tools:* and attr:<name>
+func AttributeColumn(header string) (attr string, ok bool) {
+	header = strings.TrimSpace(header)
+	lower := strings.ToLower(header)
+	switch {
+	case lower == AttrTranslatable || lower == AttrFormatted || lower == AttrProduct:
+		return lower, true
+	case strings.HasPrefix(lower, toolsColumnPrefix) && len(header) > len(toolsColumnPrefix):
+		return header, true
+	case strings.HasPrefix(lower, attrColumnPrefix) && len(header) > len(attrColumnPrefix):
+		return strings.TrimSpace(header[len(attrColumnPrefix):]), true
+	}
+	return "", false
+}
+
+var formatSpecifierRegex = regexp.MustCompile(`%(\d+\$)?[-#+0,(]*\d*(\.\d+)?[bBhHsScCdoxXeEfgGaAn%]`)
+
+// HasLiteralPercent reports whether raw contains a '%' but no format
+// specifiers, such strings need formatted="false" on android
+func HasLiteralPercent(raw string) bool {
+	return strings.Contains(raw, "%") && !formatSpecifierRegex.MatchString(raw)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestAttributeColumn(t *testing.T) {
+	tests := []struct {
+		header string
+		attr   string
+		ok     bool
+	}{
+		{header: "translatable", attr: "translatable", ok: true},
+		{header: " Formatted ", attr: "formatted", ok: true},
+		{header: "PRODUCT", attr: "product", ok: true},
+		{header: "tools:ignore", attr: "tools:ignore", ok: true},
+		{header: "Tools:keep", attr: "Tools:keep", ok: true},
+		{header: "attr:maxLength", attr: "maxLength", ok: true},
+		{header: "ATTR: note ", attr: "note", ok: true},
+		{header: "tools:"},
+		{header: "attr:"},
+		{header: "en"},
+		{header: "translatable-ish"},
+	}
+	for _, tt := range tests {
+		attr, ok := AttributeColumn(tt.header)
+		if attr != tt.attr || ok != tt.ok {
+			t.Errorf("AttributeColumn(%q) = %q, %v, want %q, %v", tt.header, attr, ok, tt.attr, tt.ok)
+		}
+	}
+}
+
+func TestHasLiteralPercent(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{raw: "plain"},
+		{raw: "100% sure", want: true},
+		{raw: "50 %", want: true},
+		{raw: "%d items"},
+		{raw: "%1$s has %2$.2f"},
+		{raw: "%d%% done"},
+		{raw: "%%"},
+	}
+	for _, tt := range tests {
+		if got := HasLiteralPercent(tt.raw); got != tt.want {
+			t.Errorf("HasLiteralPercent(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import "strings"
+
+// commentColumns are common headers of free-form source columns for
+// translators, lowercase
+var commentColumns = map[string]bool{
+	"comment":     true,
+	"comments":    true,
+	"context":     true,
+	"description": true,
+	"note":        true,
+	"notes":       true,
+	"remark":      true,
+	"remarks":     true,
+	"备注":          true,
+	"註釋":          true,
+	"注释":          true,
+	"说明":          true,
+	"說明":          true,
+	"メモ":          true,
+	"備考":          true,
+	"메모":          true,
+	"comentario":  true,
+	"comentarios": true,
+	"nota":        true,
+	"notas":       true,
+}
+
+// IsCommentColumn reports whether a source header is a free-form column, like
+// notes or context for translators, or any header starting with #
+func IsCommentColumn(header string) bool {
+	header = strings.ToLower(strings.TrimSpace(header))
+	return strings.HasPrefix(header, "#") || commentColumns[header]
+}
@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestIsCommentColumn(t *testing.T) {
+	tests := []struct {
+		header  string
+		comment bool
+	}{
+		{header: "notes", comment: true},
+		{header: " Notes ", comment: true},
+		{header: "COMMENT", comment: true},
+		{header: "Context", comment: true},
+		{header: "备注", comment: true},
+		{header: "說明", comment: true},
+		{header: "メモ", comment: true},
+		{header: "#", comment: true},
+		{header: "# for translators", comment: true},
+		{header: " #draft", comment: true},
+		{header: "en"},
+		{header: "zh-rTW"},
+		{header: "English"},
+		{header: "note taker"},
+		{header: ""},
+	}
+	for _, tt := range tests {
+		if got := IsCommentColumn(tt.header); got != tt.comment {
+			t.Errorf("IsCommentColumn(%q) = %v, want %v", tt.header, got, tt.comment)
+		}
+	}
+}
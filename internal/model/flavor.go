@@ -0,0 +1,54 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// FlavorSeparator separates a language from the product flavor a source
+// column or file applies to, e.g. zh-TW@huawei or promo@huawei.csv
+const FlavorSeparator = "@"
+
+// SplitFlavor splits a language like zh-TW@huawei into language and flavor
+func SplitFlavor(lang string) (base, flavor string) {
+	i := strings.LastIndex(lang, FlavorSeparator)
+	if i < 0 {
+		return lang, ""
+	}
+	return strings.TrimSpace(lang[:i]), strings.TrimSpace(lang[i+1:])
+}
+
+// FlavorLanguage returns the language key of a flavor, e.g. zh-TW@huawei
+func FlavorLanguage(lang, flavor string) string {
+	if flavor == "" {
+		return lang
+	}
+	return lang + FlavorSeparator + flavor
+}
+
+// FileFlavor returns the flavor of a source file named like promo@huawei.csv,
+// empty if the file applies to all flavors
+func FileFlavor(path string) string {
+	base := filepath.Base(path)
+	_, flavor := SplitFlavor(strings.TrimSuffix(base, filepath.Ext(base)))
+	return flavor
+}
+
+// SplitFlavors splits data by language into data shared by all flavors, and
+// flavor specific data by flavor
+func SplitFlavors(data map[string]map[string]string) (shared map[string]map[string]string, flavors map[string]map[string]map[string]string) {
+	shared = make(map[string]map[string]string)
+	flavors = make(map[string]map[string]map[string]string)
+	for lang, kvs := range data {
+		base, flavor := SplitFlavor(lang)
+		if flavor == "" {
+			shared[lang] = kvs
+			continue
+		}
+		if flavors[flavor] == nil {
+			flavors[flavor] = make(map[string]map[string]string)
+		}
+		flavors[flavor][base] = kvs
+	}
+	return
+}
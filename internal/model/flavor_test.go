@@ -0,0 +1,68 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFlavor(t *testing.T) {
+	tests := []struct {
+		lang   string
+		base   string
+		flavor string
+	}{
+		{lang: "zh-TW", base: "zh-TW"},
+		{lang: "zh-TW@huawei", base: "zh-TW", flavor: "huawei"},
+		{lang: "en @ google ", base: "en", flavor: "google"},
+		{lang: "a@b@c", base: "a@b", flavor: "c"},
+	}
+	for _, tt := range tests {
+		base, flavor := SplitFlavor(tt.lang)
+		if base != tt.base || flavor != tt.flavor {
+			t.Errorf("SplitFlavor(%q) = %q, %q, want %q, %q", tt.lang, base, flavor, tt.base, tt.flavor)
+		}
+		if tt.flavor != "" && FlavorLanguage(base, flavor) != base+"@"+flavor {
+			t.Errorf("FlavorLanguage(%q, %q) = %q", base, flavor, FlavorLanguage(base, flavor))
+		}
+	}
+	if got := FlavorLanguage("en", ""); got != "en" {
+		t.Errorf("FlavorLanguage(en, \"\") = %q, want en", got)
+	}
+}
+
+func TestFileFlavor(t *testing.T) {
+	tests := []struct {
+		path   string
+		flavor string
+	}{
+		{path: "i18n/promo.csv"},
+		{path: "i18n/promo@huawei.csv", flavor: "huawei"},
+		{path: "/repo/i18n@old/promo.csv"},
+		{path: "strings@google.xlsx", flavor: "google"},
+	}
+	for _, tt := range tests {
+		if got := FileFlavor(tt.path); got != tt.flavor {
+			t.Errorf("FileFlavor(%q) = %q, want %q", tt.path, got, tt.flavor)
+		}
+	}
+}
+
+func TestSplitFlavors(t *testing.T) {
+	data := map[string]map[string]string{
+		"en":        {"a": "A"},
+		"en@huawei": {"a": "A huawei"},
+		"fr@huawei": {"a": "A fr huawei"},
+		"en@google": {"b": "B google"},
+	}
+	shared, flavors := SplitFlavors(data)
+	if want := map[string]map[string]string{"en": {"a": "A"}}; !reflect.DeepEqual(shared, want) {
+		t.Errorf("shared = %v, want %v", shared, want)
+	}
+	want := map[string]map[string]map[string]string{
+		"huawei": {"en": {"a": "A huawei"}, "fr": {"a": "A fr huawei"}},
+		"google": {"en": {"b": "B google"}},
+	}
+	if !reflect.DeepEqual(flavors, want) {
+		t.Errorf("flavors = %v, want %v", flavors, want)
+	}
+}
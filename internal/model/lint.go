@@ -4,12 +4,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 )
 
 type LintResult struct {
-	Language string `json:"language"`
-	Key      string `json:"key"`
-	Desc     string `json:"desc"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Language string   `json:"language"`
+	Key      string   `json:"key"`
+	Desc     string   `json:"desc"`
+	// File, Line and Column locate the source cell, if known
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// locate sets the position of the cell of the key in lang of source
+func (lr *LintResult) locate(source *SourceFile, lang string) {
+	lr.File = source.AbsPath
+	if pos, ok := source.Position(lang, lr.Key); ok {
+		lr.Line, lr.Column = pos.Line, pos.Column
+	}
 }
 
 func (lr *LintResult) String() string {
@@ -32,6 +47,9 @@ func (s *SourceFile) Lint(linters ...Linter) (result []*LintResult) {
 		for key, str := range kvs.KVS {
 			for _, linter := range linters {
 				lintResultOfSingleLine := linter(lang, key, str)
+				for _, r := range lintResultOfSingleLine {
+					r.locate(s, lang)
+				}
 				result = append(result, lintResultOfSingleLine...)
 			}
 		}
@@ -40,8 +58,57 @@ func (s *SourceFile) Lint(linters ...Linter) (result []*LintResult) {
 	return
 }
 
+// KeyLinter checks a key across languages, values maps each language to the
+// value of the key
+type KeyLinter func(key string, values map[string]string) []*LintResult
+
+// LintKeys runs key linters on every key of data, language -> key -> value,
+// results are sorted by key
+func LintKeys(data map[string]map[string]string, linters ...KeyLinter) (result []*LintResult) {
+	byKey := make(map[string]map[string]string)
+	for lang, kvs := range data {
+		for key, value := range kvs {
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = make(map[string]string)
+			}
+			byKey[key][lang] = value
+		}
+	}
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, linter := range linters {
+			result = append(result, linter(key, byKey[key])...)
+		}
+	}
+	return
+}
+
+// LocateResults sets the source cell of results found on the merged data of
+// flavor, the first source holding the key is taken
+func LocateResults(sources []*SourceFile, flavor string, results []*LintResult) {
+	for _, r := range results {
+		if r.File != "" {
+			continue
+		}
+		lang := FlavorLanguage(r.Language, flavor)
+		for _, source := range sources {
+			if _, ok := source.Position(lang, r.Key); ok {
+				r.locate(source, lang)
+				break
+			}
+		}
+	}
+}
+
 // builtin linters
 
+// WithDefaultLinters returns a linter running the builtin per value rules,
+// results are of their default severity, see RuleSet.Apply
 func WithDefaultLinters() Linter {
 	return func(lang, key, raw string) []*LintResult {
 		builtinLinters := []Linter{
@@ -74,13 +141,19 @@ func lintFormatSpecifiers(lang, key, raw string) []*LintResult {
 
 	regList := []*regexp.Regexp{reg1, reg2, reg3, reg4, reg5, reg6}
 
-	for _, reg := range regList {
+	for i, reg := range regList {
+		rule, desc := RuleInvalidSpecifier, "invalid format specifier"
+		if i >= 2 {
+			rule, desc = RuleFullwidthPercent, "fullwidth percent sign"
+		}
 		indices := reg.FindAllIndex([]byte(raw), -1)
 		for _, index := range indices {
 			r := &LintResult{
+				Rule:     rule,
+				Severity: FindRule(rule).Severity,
 				Language: lang,
 				Key:      key,
-				Desc:     fmt.Sprintf("invalid format specifier '%v' found in lang:%v, key:%v, at pos:%v", raw[index[0]:index[1]], lang, key, index[0]),
+				Desc:     fmt.Sprintf("%v '%v' found in lang:%v, key:%v, at pos:%v", desc, raw[index[0]:index[1]], lang, key, index[0]),
 			}
 			result = append(result, r)
 		}
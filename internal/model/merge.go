@@ -89,3 +89,22 @@ func Merge(sources []*SourceFile, resolver MergeCollisionResolver) map[string]ma
 
 	return result
 }
+
+// MergeAttributes merges attributes declared in sources by resource name,
+// keys like items_count[one] share the attributes of items_count
+func MergeAttributes(sources []*SourceFile) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for _, src := range sources {
+		for key, attrs := range src.Attributes {
+			name, _ := SplitResourceKey(key)
+			if result[name] == nil {
+				result[name] = make(map[string]string)
+			}
+			for attr, value := range attrs {
+				result[name][attr] = value
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,24 @@
+package model
+
+import "strings"
+
+// ModuleColumn is the header of the source column naming the module a key
+// belongs to, used by routing rules
+const ModuleColumn = "module"
+
+// IsModuleColumn reports whether a source header is the module column
+func IsModuleColumn(header string) bool {
+	return strings.EqualFold(strings.TrimSpace(header), ModuleColumn)
+}
+
+// MergeModules merges the module column of sources by resource name, later
+// sources take precedence
+func MergeModules(sources []*SourceFile) map[string]string {
+	result := make(map[string]string)
+	for _, src := range sources {
+		for name, module := range src.Modules {
+			result[name] = module
+		}
+	}
+	return result
+}
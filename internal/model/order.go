@@ -0,0 +1,57 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// KeyOrder is the order resources appear in the sources, along with the
+// group each of them belongs to
+type KeyOrder struct {
+	Names  []string
+	Groups map[string]string
+	index  map[string]int
+}
+
+// MergeOrder merges the row order of sources, in the order of sources. The
+// group of a resource is its section, or the name of its source file
+func MergeOrder(sources []*SourceFile) *KeyOrder {
+	o := &KeyOrder{
+		Groups: make(map[string]string),
+		index:  make(map[string]int),
+	}
+	for _, src := range sources {
+		base := filepath.Base(src.AbsPath)
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+		for _, name := range src.Names {
+			if _, ok := o.index[name]; ok {
+				continue
+			}
+			o.index[name] = len(o.Names)
+			o.Names = append(o.Names, name)
+			if section := src.Sections[name]; section != "" {
+				o.Groups[name] = section
+			} else {
+				o.Groups[name] = base
+			}
+		}
+	}
+	return o
+}
+
+// Index returns the position of a resource in the sources
+func (o *KeyOrder) Index(name string) (int, bool) {
+	if o == nil {
+		return 0, false
+	}
+	i, ok := o.index[name]
+	return i, ok
+}
+
+// Group returns the group of a resource, empty if unknown
+func (o *KeyOrder) Group(name string) string {
+	if o == nil {
+		return ""
+	}
+	return o.Groups[name]
+}
@@ -0,0 +1,277 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/master-g/i18n/pkg/locale"
+)
+
+// plural quantities, in the order they are written to xml
+const (
+	QuantityZero  = "zero"
+	QuantityOne   = "one"
+	QuantityTwo   = "two"
+	QuantityFew   = "few"
+	QuantityMany  = "many"
+	QuantityOther = "other"
+)
+
+var pluralQuantities = []string{QuantityZero, QuantityOne, QuantityTwo, QuantityFew, QuantityMany, QuantityOther}
+
+// cldrPlurals holds CLDR cardinal plural categories of languages that
+// differ from the common "one, other"
+var cldrPlurals = map[string][]string{}
+
+func init() {
+	register := func(quantities []string, languages ...string) {
+		for _, lang := range languages {
+			cldrPlurals[lang] = quantities
+		}
+	}
+	register([]string{QuantityOther},
+		"bm", "bo", "dz", "hnj", "id", "ig", "ii", "in", "ja", "jbo", "jv", "jw", "kde", "kea", "km", "ko",
+		"lkt", "lo", "ms", "my", "nqo", "osa", "sah", "ses", "sg", "su", "th", "to", "tpi", "vi", "wo", "yo", "yue", "zh")
+	register([]string{QuantityZero, QuantityOne, QuantityTwo, QuantityFew, QuantityMany, QuantityOther},
+		"ar", "ars", "cy", "kw")
+	register([]string{QuantityOne, QuantityTwo, QuantityFew, QuantityMany, QuantityOther},
+		"br", "ga", "gv")
+	register([]string{QuantityOne, QuantityFew, QuantityMany, QuantityOther},
+		"be", "cs", "lt", "mt", "pl", "ru", "sk", "uk")
+	register([]string{QuantityOne, QuantityTwo, QuantityFew, QuantityOther},
+		"dsb", "gd", "hsb", "sl")
+	register([]string{QuantityOne, QuantityFew, QuantityOther},
+		"bs", "hr", "mo", "ro", "sh", "sr")
+	register([]string{QuantityOne, QuantityTwo, QuantityOther},
+		"he", "iu", "iw", "naq", "sat", "se", "sma", "smi", "smj", "smn", "sms")
+	register([]string{QuantityZero, QuantityOne, QuantityOther},
+		"ksh", "lag", "lv", "prg")
+	register([]string{QuantityOne, QuantityMany, QuantityOther},
+		"ca", "es", "fr", "it", "pt")
+}
+
+// IsPluralQuantity reports whether q is a valid plural quantity
+func IsPluralQuantity(q string) bool {
+	for _, v := range pluralQuantities {
+		if v == q {
+			return true
+		}
+	}
+	return false
+}
+
+// QuantityIndex returns the CLDR order of a quantity, -1 if it is invalid
+func QuantityIndex(q string) int {
+	for i, v := range pluralQuantities {
+		if v == q {
+			return i
+		}
+	}
+	return -1
+}
+
+// PluralQuantities returns the plural quantities used by lang under CLDR rules
+func PluralQuantities(lang string) []string {
+	if q, ok := cldrPlurals[baseLanguage(lang)]; ok {
+		return q
+	}
+	return []string{QuantityOne, QuantityOther}
+}
+
+// SortQuantities sorts quantities in CLDR order, unknown ones go last
+func SortQuantities(quantities []string) []string {
+	set := make(map[string]bool, len(quantities))
+	for _, q := range quantities {
+		set[q] = true
+	}
+	sorted := make([]string, 0, len(quantities))
+	for _, q := range pluralQuantities {
+		if set[q] {
+			sorted = append(sorted, q)
+			delete(set, q)
+		}
+	}
+	for _, q := range quantities {
+		if set[q] {
+			sorted = append(sorted, q)
+			delete(set, q)
+		}
+	}
+	return sorted
+}
+
+// baseLanguage returns the language part of a locale, e.g. zh-rTW -> zh,
+// b+sr+Latn -> sr
+func baseLanguage(lang string) string {
+	if l, err := locale.Parse(lang); err == nil {
+		return l.Language
+	}
+	lang = strings.TrimPrefix(lang, "b+")
+	if i := strings.IndexAny(lang, "-_+"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.ToLower(lang)
+}
+
+var resourceKeyRegex = regexp.MustCompile(`^(.+)\[([^\[\]]*)\]$`)
+
+// SplitResourceKey splits a source key like items_count[one] into its
+// resource name and selector, the selector is empty for plain strings
+func SplitResourceKey(key string) (name, selector string) {
+	m := resourceKeyRegex.FindStringSubmatch(key)
+	if m == nil {
+		return key, ""
+	}
+	return m[1], m[2]
+}
+
+// PluralKey returns the source key of a plural quantity
+func PluralKey(name, quantity string) string {
+	return fmt.Sprintf("%v[%v]", name, quantity)
+}
+
+var (
+	icuPluralHeadRegex   = regexp.MustCompile(`^\{\s*[\w.]+\s*,\s*plural\s*,`)
+	icuNestedPluralRegex = regexp.MustCompile(`^\{\s*[\w.]+\s*,\s*(plural|selectordinal)\s*,`)
+)
+
+// ParseICUPlural parses an ICU plural message like
+// {count, plural, one {# item} other {# items}} into quantity forms,
+// '#' is replaced by %d and ICU apostrophe quoting is resolved. ok is false
+// if raw is not a plural message. Selectors must be plural categories,
+// explicit ones like =0 match a number rather than a category and have no
+// android equivalent, they are rejected like offset: and nested plurals
+func ParseICUPlural(raw string) (forms map[string]string, ok bool, err error) {
+	raw = strings.TrimSpace(raw)
+	head := icuPluralHeadRegex.FindString(raw)
+	if head == "" || !strings.HasSuffix(raw, "}") {
+		return nil, false, nil
+	}
+
+	body := []rune(raw[len(head) : len(raw)-1])
+	skipSpaces := func(i int) int {
+		for i < len(body) && unicode.IsSpace(body[i]) {
+			i++
+		}
+		return i
+	}
+	forms = make(map[string]string)
+	for i := skipSpaces(0); i < len(body); i = skipSpaces(i) {
+		// selector
+		start := i
+		for i < len(body) && body[i] != '{' && !unicode.IsSpace(body[i]) {
+			i++
+		}
+		selector := string(body[start:i])
+		switch {
+		case strings.HasPrefix(selector, "offset:"):
+			return nil, true, fmt.Errorf("plural offset %q is not supported", selector)
+		case strings.HasPrefix(selector, "="):
+			return nil, true, fmt.Errorf("explicit selector %v is not supported, use a plural category", selector)
+		case !IsPluralQuantity(selector):
+			return nil, true, fmt.Errorf("invalid plural selector %q", selector)
+		}
+		if _, dup := forms[selector]; dup {
+			return nil, true, fmt.Errorf("plural selector %v appears twice", selector)
+		}
+		i = skipSpaces(i)
+		if i >= len(body) || body[i] != '{' {
+			return nil, true, fmt.Errorf("no message after plural selector %v", selector)
+		}
+
+		// message, braces may nest
+		var message string
+		message, i, err = icuMessage(body, i)
+		if err != nil {
+			return nil, true, fmt.Errorf("%v in plural selector %v", err, selector)
+		}
+		forms[selector] = message
+	}
+
+	if len(forms) == 0 {
+		return nil, true, errors.New("plural message has no selector")
+	}
+	return forms, true, nil
+}
+
+// icuMessage reads the message starting with the '{' at body[i] and returns
+// it unquoted, with '#' replaced by %d, along with the index after its '}'
+func icuMessage(body []rune, i int) (message string, next int, err error) {
+	sb := &strings.Builder{}
+	depth := 0
+	for ; i < len(body); i++ {
+		r := body[i]
+		switch r {
+		case '{':
+			depth++
+			if depth == 1 {
+				continue
+			}
+			if icuNestedPluralRegex.MatchString(string(body[i:])) {
+				return "", 0, errors.New("nested plural is not supported")
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return sb.String(), i + 1, nil
+			}
+		case '#':
+			sb.WriteString("%d")
+			continue
+		case '\'':
+			// '' is an apostrophe, a ' before a syntax character quotes
+			// the text up to the next single '
+			if i+1 < len(body) && body[i+1] == '\'' {
+				sb.WriteRune('\'')
+				i++
+				continue
+			}
+			if i+1 >= len(body) || !strings.ContainsRune("{}#|", body[i+1]) {
+				break
+			}
+			for i++; ; i++ {
+				if i >= len(body) {
+					return "", 0, errors.New("unterminated quote")
+				}
+				if body[i] == '\'' {
+					if i+1 < len(body) && body[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i++
+						continue
+					}
+					break
+				}
+				sb.WriteRune(body[i])
+			}
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return "", 0, errors.New("unbalanced braces")
+}
+
+// CheckPluralQuantities reports plural quantities in kvs that lang does not
+// use under CLDR rules, those are never selected at runtime
+func CheckPluralQuantities(lang string, kvs map[string]string) (result []*LintResult) {
+	used := make(map[string]bool)
+	for _, q := range PluralQuantities(lang) {
+		used[q] = true
+	}
+
+	for key := range kvs {
+		name, selector := SplitResourceKey(key)
+		if selector == "" || !IsPluralQuantity(selector) || used[selector] {
+			continue
+		}
+		result = append(result, &LintResult{
+			Language: lang,
+			Key:      key,
+			Desc:     fmt.Sprintf("quantity '%v' of plurals '%v' is not used in lang:%v, expected one of %v", selector, name, lang, PluralQuantities(lang)),
+		})
+	}
+
+	return
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseICUPlural(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]string
+		notICU  bool
+		wantErr string
+	}{
+		{
+			name: "one and other",
+			raw:  "{count, plural, one {# item} other {# items}}",
+			want: map[string]string{"one": "%d item", "other": "%d items"},
+		},
+		{
+			name: "spaces, tabs and newlines",
+			raw:  "{ count ,plural,\n\tone\t{# item}\n\tother\n{# items}\n}",
+			want: map[string]string{"one": "%d item", "other": "%d items"},
+		},
+		{
+			name: "no space before message",
+			raw:  "{n, plural, few{# штуки} many{# штук} other{# штуки}}",
+			want: map[string]string{"few": "%d штуки", "many": "%d штук", "other": "%d штуки"},
+		},
+		{
+			name: "nested select",
+			raw:  "{n, plural, other {{g, select, male {his # items} other {their # items}}}}",
+			want: map[string]string{"other": "{g, select, male {his %d items} other {their %d items}}"},
+		},
+		{
+			name: "quoting",
+			raw:  "{n, plural, one {it''s '{one}' '#' item} other {don't '#'s}}",
+			want: map[string]string{"one": "it's {one} # item", "other": "don't #s"},
+		},
+		{name: "plain text", raw: "3 items", notICU: true},
+		{name: "select", raw: "{g, select, male {he} other {they}}", notICU: true},
+		{name: "plain argument", raw: "{count}", notICU: true},
+		{name: "explicit zero", raw: "{n, plural, =0 {none} other {#}}", wantErr: "explicit selector =0"},
+		{name: "explicit one", raw: "{n, plural, =1 {one} other {#}}", wantErr: "explicit selector =1"},
+		{name: "offset", raw: "{n, plural, offset:1 one {#} other {#}}", wantErr: "offset"},
+		{name: "unknown category", raw: "{n, plural, single {#} other {#}}", wantErr: `invalid plural selector "single"`},
+		{name: "repeated category", raw: "{n, plural, other {a} other {b}}", wantErr: "appears twice"},
+		{name: "nested plural", raw: "{n, plural, other {{m, plural, other {#}}}}", wantErr: "nested plural"},
+		{name: "unbalanced", raw: "{n, plural, other {# items}", wantErr: "unbalanced"},
+		{name: "unterminated quote", raw: "{n, plural, other {'{# items}}", wantErr: "unterminated quote"},
+		{name: "no message", raw: "{n, plural, one other {#}}", wantErr: "no message after plural selector one"},
+		{name: "empty", raw: "{n, plural, }", wantErr: "no selector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, ok, err := ParseICUPlural(tt.raw)
+			if tt.wantErr != "" {
+				if !ok || err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseICUPlural(%q) = %v, %v, err:%v, want %q", tt.raw, forms, ok, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseICUPlural(%q) err:%v", tt.raw, err)
+			}
+			if ok == tt.notICU {
+				t.Fatalf("ParseICUPlural(%q) ok = %v", tt.raw, ok)
+			}
+			if !reflect.DeepEqual(forms, tt.want) {
+				t.Errorf("ParseICUPlural(%q) = %q, want %q", tt.raw, forms, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitResourceKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		name     string
+		selector string
+	}{
+		{key: "title", name: "title"},
+		{key: "count[one]", name: "count", selector: "one"},
+		{key: "reasons[12]", name: "reasons", selector: "12"},
+		{key: "reasons[]", name: "reasons"},
+		{key: "a[b][c]", name: "a[b]", selector: "c"},
+		{key: "a[b]c", name: "a[b]c"},
+		{key: "[one]", name: "[one]"},
+	}
+	for _, tt := range tests {
+		name, selector := SplitResourceKey(tt.key)
+		if name != tt.name || selector != tt.selector {
+			t.Errorf("SplitResourceKey(%q) = %q, %q, want %q, %q", tt.key, name, selector, tt.name, tt.selector)
+		}
+	}
+}
+
+func TestPluralQuantities(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{lang: "en", want: "one,other"},
+		{lang: "de-rAT", want: "one,other"},
+		{lang: "zh", want: "other"},
+		{lang: "zh-rTW", want: "other"},
+		{lang: "ja", want: "other"},
+		{lang: "in", want: "other"},
+		{lang: "ar", want: "zero,one,two,few,many,other"},
+		{lang: "ru", want: "one,few,many,other"},
+		{lang: "pl", want: "one,few,many,other"},
+		{lang: "b+sr+Latn", want: "one,few,other"},
+		{lang: "iw", want: "one,two,other"},
+		{lang: "he", want: "one,two,other"},
+		{lang: "lv", want: "zero,one,other"},
+		{lang: "fr", want: "one,many,other"},
+		{lang: "pt_BR", want: "one,many,other"},
+		{lang: "sl", want: "one,two,few,other"},
+		{lang: "ga", want: "one,two,few,many,other"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(PluralQuantities(tt.lang), ","); got != tt.want {
+			t.Errorf("PluralQuantities(%q) = %v, want %v", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestSortQuantities(t *testing.T) {
+	got := SortQuantities([]string{"other", "x", "few", "one", "zero"})
+	if want := "zero,one,few,other,x"; strings.Join(got, ",") != want {
+		t.Errorf("SortQuantities() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckPluralQuantities(t *testing.T) {
+	kvs := map[string]string{
+		"a[zero]":  "none",
+		"a[one]":   "one",
+		"a[other]": "many",
+		"b":        "plain",
+		"c[0]":     "item",
+	}
+	var keys []string
+	for _, r := range CheckPluralQuantities("en", kvs) {
+		keys = append(keys, r.Key)
+	}
+	if strings.Join(keys, ",") != "a[zero]" {
+		t.Errorf("CheckPluralQuantities(en) reports %v, want a[zero]", keys)
+	}
+	if result := CheckPluralQuantities("lv", kvs); len(result) != 0 {
+		t.Errorf("CheckPluralQuantities(lv) reports %d results, want none", len(result))
+	}
+}
@@ -0,0 +1,237 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/master-g/i18n/pkg/locale"
+)
+
+// Severity is how serious a lint finding is, only errors stop a run
+type Severity string
+
+const (
+	// SeverityError stops the run
+	SeverityError Severity = "error"
+	// SeverityWarning is reported, the run goes on
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is reported as information
+	SeverityInfo Severity = "info"
+	// SeverityOff turns a rule off
+	SeverityOff Severity = "off"
+)
+
+func (s Severity) valid() bool {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return true
+	}
+	return false
+}
+
+// IDs of the builtin lint rules, they never change
+const (
+	RuleInvalidSpecifier   = "invalid-specifier"
+	RuleFullwidthPercent   = "fullwidth-percent"
+	RulePlaceholderMissing = "placeholder-missing"
+	RulePlaceholderExtra   = "placeholder-extra"
+	RulePlaceholderType    = "placeholder-type"
+	RulePlaceholderOrder   = "placeholder-order"
+	RulePlaceholderMixed   = "placeholder-mixed"
+)
+
+// Rule is a lint rule
+type Rule struct {
+	ID       string
+	Severity Severity
+	Desc     string
+}
+
+// Rules are the builtin lint rules with their default severities
+var Rules = []*Rule{
+	{ID: RuleInvalidSpecifier, Severity: SeverityError, Desc: "malformed format specifier like s% or $1%s"},
+	{ID: RuleFullwidthPercent, Severity: SeverityError, Desc: "fullwidth percent sign in a format specifier like ％s"},
+	{ID: RulePlaceholderMissing, Severity: SeverityWarning, Desc: "format specifier of the base language missing"},
+	{ID: RulePlaceholderExtra, Severity: SeverityError, Desc: "format specifier not in the base language"},
+	{ID: RulePlaceholderType, Severity: SeverityError, Desc: "format specifier of another type than the base language"},
+	{ID: RulePlaceholderOrder, Severity: SeverityError, Desc: "non-positional format specifiers in another order than the base language"},
+	{ID: RulePlaceholderMixed, Severity: SeverityWarning, Desc: "positional and non-positional format specifiers mixed"},
+}
+
+// FindRule returns the builtin rule of id, nil if there is none
+func FindRule(id string) *Rule {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// RuleConfig configures a lint rule. Languages and keys limit the rule to
+// them, excluded ones are skipped. Keys are patterns like "title_*", see
+// path.Match. Languages are locales in any form, like zh-TW, zh-rTW or
+// zh_TW, or language names like 繁体中文, a language without flavor applies
+// to its flavors too
+type RuleConfig struct {
+	Severity         Severity `mapstructure:"severity" json:"severity,omitempty"`
+	Languages        []string `mapstructure:"languages" json:"languages,omitempty"`
+	ExcludeLanguages []string `mapstructure:"exclude-languages" json:"exclude-languages,omitempty"`
+	Keys             []string `mapstructure:"keys" json:"keys,omitempty"`
+	ExcludeKeys      []string `mapstructure:"exclude-keys" json:"exclude-keys,omitempty"`
+
+	// normalized Languages and ExcludeLanguages
+	languages        []string
+	excludeLanguages []string
+}
+
+// languageKey normalizes a source or config language for matching, e.g.
+// zh-rTW and 繁体中文 are both zh-TW, ok is false if lang is neither a
+// locale nor a known language name
+func languageKey(lang string) (key string, ok bool) {
+	base, flavor := SplitFlavor(strings.TrimSpace(lang))
+	if l, err := locale.Parse(base); err == nil {
+		return FlavorLanguage(l.String(), flavor), true
+	}
+	if l, found := locale.LookupName(base); found {
+		return FlavorLanguage(l.String(), flavor), true
+	}
+	return FlavorLanguage(strings.ToLower(base), flavor), false
+}
+
+// unknownLanguages returns the configured languages that are neither
+// locales nor known language names
+func (c *RuleConfig) unknownLanguages() (unknown []string) {
+	for _, lang := range append(append([]string{}, c.Languages...), c.ExcludeLanguages...) {
+		if _, ok := languageKey(lang); !ok {
+			unknown = append(unknown, lang)
+		}
+	}
+	return
+}
+
+func (c *RuleConfig) validate() error {
+	if c.Severity != "" && !c.Severity.valid() {
+		return fmt.Errorf("invalid severity %q, use error, warning, info or off", c.Severity)
+	}
+	for _, pattern := range append(append([]string{}, c.Keys...), c.ExcludeKeys...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q, err:%v", pattern, err)
+		}
+	}
+	c.languages = c.languages[:0]
+	for _, lang := range c.Languages {
+		key, _ := languageKey(lang)
+		c.languages = append(c.languages, key)
+	}
+	c.excludeLanguages = c.excludeLanguages[:0]
+	for _, lang := range c.ExcludeLanguages {
+		key, _ := languageKey(lang)
+		c.excludeLanguages = append(c.excludeLanguages, key)
+	}
+	return nil
+}
+
+func (c *RuleConfig) applies(lang, key string) bool {
+	lang, _ = languageKey(lang)
+	base, _ := SplitFlavor(lang)
+	matchLang := func(langs []string) bool {
+		for _, v := range langs {
+			if v == lang || v == base {
+				return true
+			}
+		}
+		return false
+	}
+	matchKey := func(patterns []string) bool {
+		for _, v := range patterns {
+			if ok, _ := path.Match(v, key); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(c.languages) > 0 && !matchLang(c.languages) {
+		return false
+	}
+	if len(c.Keys) > 0 && !matchKey(c.Keys) {
+		return false
+	}
+	return !matchLang(c.excludeLanguages) && !matchKey(c.ExcludeKeys)
+}
+
+// RuleSet is the builtin lint rules with their config
+type RuleSet struct {
+	configs map[string]*RuleConfig
+}
+
+// NewRuleSet returns the rule set of configs by rule id, unknown rules and
+// invalid configs are errors
+func NewRuleSet(configs map[string]*RuleConfig) (*RuleSet, error) {
+	rs := &RuleSet{configs: make(map[string]*RuleConfig, len(configs))}
+	for id, config := range configs {
+		if FindRule(id) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+		if config == nil {
+			continue
+		}
+		if err := config.validate(); err != nil {
+			return nil, fmt.Errorf("lint rule %v: %v", id, err)
+		}
+		rs.configs[id] = config
+	}
+	return rs, nil
+}
+
+// UnknownLanguages returns the configured languages, by rule id, that are
+// neither locales nor known language names, they only match source columns
+// of the same name
+func (rs *RuleSet) UnknownLanguages() map[string][]string {
+	unknown := make(map[string][]string)
+	for id, config := range rs.configs {
+		if langs := config.unknownLanguages(); len(langs) > 0 {
+			unknown[id] = langs
+		}
+	}
+	return unknown
+}
+
+// Severity returns the configured severity of rule id
+func (rs *RuleSet) Severity(id string) Severity {
+	if config, ok := rs.configs[id]; ok && config.Severity != "" {
+		return config.Severity
+	}
+	if rule := FindRule(id); rule != nil {
+		return rule.Severity
+	}
+	return SeverityError
+}
+
+// Apply sets the severity of results by their rules, results of rules that
+// are off or do not apply to their language and key are dropped
+func (rs *RuleSet) Apply(results []*LintResult) (kept []*LintResult) {
+	for _, result := range results {
+		severity := rs.Severity(result.Rule)
+		if severity == SeverityOff {
+			continue
+		}
+		if config, ok := rs.configs[result.Rule]; ok && !config.applies(result.Language, result.Key) {
+			continue
+		}
+		result.Severity = severity
+		kept = append(kept, result)
+	}
+	return
+}
+
+// CountErrors returns the number of results of error severity
+func CountErrors(results []*LintResult) (count int) {
+	for _, result := range results {
+		if result.Severity == SeverityError {
+			count++
+		}
+	}
+	return
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNewRuleSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs map[string]*RuleConfig
+		wantErr string
+	}{
+		{name: "empty"},
+		{name: "nil config", configs: map[string]*RuleConfig{RulePlaceholderMissing: nil}},
+		{name: "unknown rule", configs: map[string]*RuleConfig{"no-such-rule": {}}, wantErr: "unknown lint rule"},
+		{name: "invalid severity", configs: map[string]*RuleConfig{RulePlaceholderMissing: {Severity: "fatal"}}, wantErr: "invalid severity"},
+		{name: "invalid key pattern", configs: map[string]*RuleConfig{RulePlaceholderMissing: {Keys: []string{"["}}}, wantErr: "invalid key pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleSet(tt.configs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("NewRuleSet() err:%v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRuleSet() err:%v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleSetApply(t *testing.T) {
+	results := func() []*LintResult {
+		return []*LintResult{
+			{Rule: RulePlaceholderMissing, Language: "zh-TW", Key: "title_main"},
+			{Rule: RulePlaceholderMissing, Language: "fr", Key: "body"},
+			{Rule: RulePlaceholderMissing, Language: "fr@huawei", Key: "body"},
+			{Rule: RulePlaceholderExtra, Language: "繁体中文", Key: "body"},
+			{Rule: RulePlaceholderMixed, Language: "de", Key: "body"},
+		}
+	}
+	tests := []struct {
+		name    string
+		configs map[string]*RuleConfig
+		// want holds kept results like "rule lang key severity"
+		want []string
+	}{
+		{
+			name: "default severities",
+			want: []string{
+				"placeholder-extra 繁体中文 body error",
+				"placeholder-missing fr body warning",
+				"placeholder-missing fr@huawei body warning",
+				"placeholder-missing zh-TW title_main warning",
+				"placeholder-mixed de body warning",
+			},
+		},
+		{
+			name: "severity override and off",
+			configs: map[string]*RuleConfig{
+				RulePlaceholderMissing: {Severity: SeverityError},
+				RulePlaceholderMixed:   {Severity: SeverityOff},
+			},
+			want: []string{
+				"placeholder-extra 繁体中文 body error",
+				"placeholder-missing fr body error",
+				"placeholder-missing fr@huawei body error",
+				"placeholder-missing zh-TW title_main error",
+			},
+		},
+		{
+			name: "android and underscore languages",
+			configs: map[string]*RuleConfig{
+				RulePlaceholderMissing: {Languages: []string{"zh-rTW"}},
+				RulePlaceholderExtra:   {Languages: []string{"zh_TW"}},
+			},
+			want: []string{
+				"placeholder-extra 繁体中文 body error",
+				"placeholder-missing zh-TW title_main warning",
+				"placeholder-mixed de body warning",
+			},
+		},
+		{
+			name: "language name",
+			configs: map[string]*RuleConfig{
+				RulePlaceholderMissing: {ExcludeLanguages: []string{"繁体中文", "FR"}},
+			},
+			want: []string{
+				"placeholder-extra 繁体中文 body error",
+				"placeholder-mixed de body warning",
+			},
+		},
+		{
+			name: "flavor",
+			configs: map[string]*RuleConfig{
+				RulePlaceholderMissing: {Languages: []string{"fr@huawei"}},
+			},
+			want: []string{
+				"placeholder-extra 繁体中文 body error",
+				"placeholder-missing fr@huawei body warning",
+				"placeholder-mixed de body warning",
+			},
+		},
+		{
+			name: "keys",
+			configs: map[string]*RuleConfig{
+				RulePlaceholderMissing: {Keys: []string{"title_*"}},
+				RulePlaceholderMixed:   {ExcludeKeys: []string{"b*"}},
+			},
+			want: []string{
+				"placeholder-extra 繁体中文 body error",
+				"placeholder-missing zh-TW title_main warning",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := NewRuleSet(tt.configs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range rs.Apply(results()) {
+				got = append(got, strings.Join([]string{r.Rule, r.Language, r.Key, string(r.Severity)}, " "))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() =\n%v\nwant\n%v", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRuleSetUnknownLanguages(t *testing.T) {
+	rs, err := NewRuleSet(map[string]*RuleConfig{
+		RulePlaceholderMissing: {Languages: []string{"zh-rTW", "Klingon"}, ExcludeLanguages: []string{"简体中文", "xx-bad!"}},
+		RulePlaceholderExtra:   {Languages: []string{"fr"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{RulePlaceholderMissing: {"Klingon", "xx-bad!"}}
+	if got := rs.UnknownLanguages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownLanguages() = %v, want %v", got, want)
+	}
+}
@@ -16,9 +16,57 @@ const (
 )
 
 type SourceFile struct {
-	Type      SourceFileType          `json:"type"`
-	AbsPath   string                  `json:"path"`
-	Languages map[string]*LanguageKVS `json:"languages"`
+	Type       SourceFileType               `json:"type"`
+	AbsPath    string                       `json:"path"`
+	Languages  map[string]*LanguageKVS      `json:"languages"`
+	Attributes map[string]map[string]string `json:"attributes,omitempty"`
+	// Modules maps resource names to the value of their module column
+	Modules map[string]string `json:"modules,omitempty"`
+	// Names holds the resource names in row order
+	Names []string `json:"names,omitempty"`
+	// Sections maps resource names to the section header rows they follow
+	Sections map[string]string `json:"sections,omitempty"`
+	// Ignores holds the lint rules ignored by the ignore column and comments
+	Ignores Ignores `json:"ignores,omitempty"`
+	// Positions maps languages to the cell positions of their keys
+	Positions map[string]map[string]Position `json:"-"`
+}
+
+// Position is the line and column of a source cell, starting at 1, columns
+// are counted in unicode code points
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// SetPosition records the cell position of key in lang
+func (s *SourceFile) SetPosition(lang, key string, pos Position) {
+	if s.Positions == nil {
+		s.Positions = make(map[string]map[string]Position)
+	}
+	if s.Positions[lang] == nil {
+		s.Positions[lang] = make(map[string]Position)
+	}
+	s.Positions[lang][key] = pos
+}
+
+// Position returns the cell position of key in lang
+func (s *SourceFile) Position(lang, key string) (pos Position, ok bool) {
+	pos, ok = s.Positions[lang][key]
+	return
+}
+
+// AddKey records the resource of a key in row order, under section
+func (s *SourceFile) AddKey(key, section string) {
+	name, _ := SplitResourceKey(key)
+	if s.Sections == nil {
+		s.Sections = make(map[string]string)
+	}
+	if _, ok := s.Sections[name]; ok {
+		return
+	}
+	s.Names = append(s.Names, name)
+	s.Sections[name] = section
 }
 
 func (s *SourceFile) String() string {
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// specifier is a format specifier taking an argument
+type specifier struct {
+	text  string
+	index int
+	conv  byte
+	// count is the number of times the argument is used
+	count int
+}
+
+// class groups conversions taking the same kind of argument
+func (s *specifier) class() byte {
+	switch conv := s.conv | 0x20; conv {
+	case 'd', 'o', 'x':
+		return 'd'
+	case 'e', 'f', 'g', 'a':
+		return 'f'
+	default:
+		return conv
+	}
+}
+
+// parseSpecifiers returns the format specifiers of raw by argument index,
+// non-positional ones take the next index, the first one of an index is kept
+// along with their count. mixed reports whether raw holds both positional and
+// non-positional specifiers
+func parseSpecifiers(raw string) (args map[int]*specifier, mixed bool) {
+	args = make(map[int]*specifier)
+	positional, next := false, 0
+	sequential := false
+	for _, m := range formatSpecifierRegex.FindAllStringSubmatch(raw, -1) {
+		conv := m[0][len(m[0])-1]
+		if conv == '%' || conv == 'n' {
+			continue
+		}
+		s := &specifier{text: m[0], conv: conv}
+		if m[1] != "" {
+			positional = true
+			s.index, _ = strconv.Atoi(strings.TrimSuffix(m[1], "$"))
+		} else {
+			sequential = true
+			next++
+			s.index = next
+		}
+		if _, ok := args[s.index]; !ok {
+			args[s.index] = s
+		}
+		args[s.index].count++
+	}
+	return args, positional && sequential
+}
+
+func sortedIndices(args map[int]*specifier) []int {
+	indices := make([]int, 0, len(args))
+	for i := range args {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// sameClasses reports whether a and b take the same kinds of arguments,
+// regardless of their order
+func sameClasses(a, b map[int]*specifier) bool {
+	count := make(map[byte]int)
+	for _, s := range a {
+		count[s.class()]++
+	}
+	for _, s := range b {
+		count[s.class()]--
+	}
+	for _, v := range count {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LintPlaceholders returns a key linter comparing the format specifiers of
+// every language with the base language: missing and extra arguments, their
+// counts, and arguments of another type. baseData holds the values of the
+// base language, plural quantities are compared with its other form if any,
+// as they may omit the count only their extra arguments and types are checked
+func LintPlaceholders(base string, baseData map[string]string) KeyLinter {
+	return func(key string, values map[string]string) (result []*LintResult) {
+		langs := make([]string, 0, len(values))
+		for lang := range values {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+
+		add := func(lang, rule, format string, args ...interface{}) {
+			result = append(result, &LintResult{
+				Rule:     rule,
+				Severity: FindRule(rule).Severity,
+				Language: lang,
+				Key:      key,
+				Desc:     fmt.Sprintf(format, args...) + fmt.Sprintf(", lang:%v, key:%v", lang, key),
+			})
+		}
+
+		parsed := make(map[string]map[int]*specifier, len(langs))
+		for _, lang := range langs {
+			var mixed bool
+			parsed[lang], mixed = parseSpecifiers(values[lang])
+			if mixed {
+				add(lang, RulePlaceholderMixed, "positional and non-positional format specifiers mixed")
+			}
+		}
+
+		// the base value, or the other form of a plural quantity
+		refKey := key
+		name, selector := SplitResourceKey(key)
+		plural := IsPluralQuantity(selector)
+		if _, ok := baseData[PluralKey(name, QuantityOther)]; plural && ok {
+			refKey = PluralKey(name, QuantityOther)
+		}
+		refValue, ok := baseData[refKey]
+		if !ok {
+			return
+		}
+		baseArgs, _ := parseSpecifiers(refValue)
+		refName := base
+		if refKey != key {
+			refName = fmt.Sprintf("%v %v", base, QuantityOther)
+		}
+
+		for _, lang := range langs {
+			if lang == base && refKey == key {
+				continue
+			}
+			args := parsed[lang]
+
+			var retyped []int
+			for _, i := range sortedIndices(baseArgs) {
+				s, ok := args[i]
+				if !ok {
+					if !plural {
+						add(lang, RulePlaceholderMissing, "format specifier %v of %v missing", baseArgs[i].text, refName)
+					}
+					continue
+				}
+				if s.class() != baseArgs[i].class() {
+					retyped = append(retyped, i)
+				}
+				switch {
+				case s.count < baseArgs[i].count && !plural:
+					add(lang, RulePlaceholderMissing, "format specifier %v used %d time(s), %d in %v", s.text, s.count, baseArgs[i].count, refName)
+				case s.count > baseArgs[i].count:
+					add(lang, RulePlaceholderExtra, "format specifier %v used %d time(s), %d in %v", s.text, s.count, baseArgs[i].count, refName)
+				}
+			}
+			for _, i := range sortedIndices(args) {
+				if _, ok := baseArgs[i]; !ok {
+					add(lang, RulePlaceholderExtra, "extra format specifier %v not in %v", args[i].text, refName)
+				}
+			}
+			if len(retyped) == 0 {
+				continue
+			}
+			if len(args) == len(baseArgs) && sameClasses(args, baseArgs) {
+				add(lang, RulePlaceholderOrder, "format specifiers in another order than %v, use positional ones like %%1$s", refName)
+				continue
+			}
+			for _, i := range retyped {
+				add(lang, RulePlaceholderType, "format specifier %v does not match %v of %v", args[i].text, baseArgs[i].text, refName)
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLintPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]map[string]string
+		// want holds rule ids with languages, like placeholder-extra fr
+		want []string
+	}{
+		{
+			name: "same specifiers",
+			data: map[string]map[string]string{
+				"en": {"k": "%1$s has %2$d"},
+				"fr": {"k": "%2$d pour %1$s"},
+			},
+		},
+		{
+			name: "missing and extra",
+			data: map[string]map[string]string{
+				"en": {"k": "%1$s has %2$d"},
+				"fr": {"k": "%1$s"},
+				"de": {"k": "%1$s %2$d %3$s"},
+			},
+			want: []string{"placeholder-extra de", "placeholder-missing fr"},
+		},
+		{
+			name: "repeated specifier",
+			data: map[string]map[string]string{
+				"en": {"k": "%1$s and %1$s"},
+				"fr": {"k": "%1$s"},
+				"de": {"k": "%1$s %1$s %1$s"},
+			},
+			want: []string{"placeholder-extra de", "placeholder-missing fr"},
+		},
+		{
+			name: "type",
+			data: map[string]map[string]string{
+				"en": {"k": "%1$s has %2$d"},
+				"fr": {"k": "%1$d has %2$d"},
+			},
+			want: []string{"placeholder-type fr"},
+		},
+		{
+			name: "order",
+			data: map[string]map[string]string{
+				"en": {"k": "%s has %d"},
+				"fr": {"k": "%d pour %s"},
+			},
+			want: []string{"placeholder-order fr"},
+		},
+		{
+			name: "mixed",
+			data: map[string]map[string]string{
+				"en": {"k": "%1$s has %d"},
+			},
+			want: []string{"placeholder-mixed en"},
+		},
+		{
+			name: "plural may omit the count",
+			data: map[string]map[string]string{
+				"en": {"k[one]": "one item", "k[other]": "%d items"},
+				"ru": {"k[one]": "один", "k[few]": "%d штуки", "k[many]": "%d штук"},
+			},
+		},
+		{
+			name: "plural quantity missing from the base",
+			data: map[string]map[string]string{
+				"en": {"k[one]": "one item", "k[other]": "%d items"},
+				"ru": {"k[few]": "%d штуки %s", "k[many]": "%s штук"},
+			},
+			want: []string{"placeholder-extra ru", "placeholder-type ru"},
+		},
+		{
+			name: "plural extra in the base",
+			data: map[string]map[string]string{
+				"en": {"k[one]": "%d item by %s", "k[other]": "%d items"},
+			},
+			want: []string{"placeholder-extra en"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range LintKeys(tt.data, LintPlaceholders("en", tt.data["en"])) {
+				got = append(got, r.Rule+" "+r.Language)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("LintPlaceholders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IgnoreColumn is the header of the source column listing the lint rules
+// ignored for a key
+const IgnoreColumn = "ignore"
+
+// IgnoreComment starts a comment row ignoring lint rules for the next key,
+// like "# lint-ignore: invalid-specifier"
+const IgnoreComment = "lint-ignore"
+
+// IgnoreAll ignores every lint rule
+const IgnoreAll = "*"
+
+// IsIgnoreColumn reports whether a source header is the ignore column
+func IsIgnoreColumn(header string) bool {
+	return strings.EqualFold(strings.TrimSpace(header), IgnoreColumn)
+}
+
+// ParseIgnore parses the lint rules of an ignore cell or comment, separated
+// by commas or spaces, * ignores all of them
+func ParseIgnore(cell string) (rules []string, err error) {
+	fields := strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, rule := range fields {
+		if rule != IgnoreAll && FindRule(rule) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q", rule)
+		}
+		rules = append(rules, rule)
+	}
+	return
+}
+
+// Ignores maps resource names to the lint rules ignored for them
+type Ignores map[string][]string
+
+// Add ignores rules for the resource of key
+func (ig Ignores) Add(key string, rules ...string) {
+	name, _ := SplitResourceKey(key)
+	ig[name] = append(ig[name], rules...)
+}
+
+// Ignored reports whether the rule of result is ignored for its key
+func (ig Ignores) Ignored(result *LintResult) bool {
+	name, _ := SplitResourceKey(result.Key)
+	for _, rule := range ig[name] {
+		if rule == IgnoreAll || rule == result.Rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Suppress splits results into the ones kept and the ones ignored
+func (ig Ignores) Suppress(results []*LintResult) (kept, suppressed []*LintResult) {
+	for _, result := range results {
+		if ig.Ignored(result) {
+			suppressed = append(suppressed, result)
+		} else {
+			kept = append(kept, result)
+		}
+	}
+	return
+}
+
+// MergeIgnores merges the ignored rules of sources by resource name
+func MergeIgnores(sources []*SourceFile) Ignores {
+	result := make(Ignores)
+	for _, src := range sources {
+		for name, rules := range src.Ignores {
+			result[name] = append(result[name], rules...)
+		}
+	}
+	return result
+}
@@ -1,41 +1,54 @@
 package parser
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
-	"os"
+	"io/ioutil"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/pkg/locale"
 )
 
 type CollisionResolver func(path, key, pre, cur string) string
 
 func LoadCSV(p string, collisionResolver CollisionResolver) (ret *model.SourceFile, err error) {
-	var csvFile *os.File
 	if !filepath.IsAbs(p) {
 		p, err = filepath.Abs(p)
 		if err != nil {
 			return
 		}
 	}
-	csvFile, err = os.Open(p)
+	var raw []byte
+	raw, err = ioutil.ReadFile(p)
 	if err != nil {
 		return
 	}
-	defer func() {
-		err2 := csvFile.Close()
-		if err == nil {
-			err = err2
-		}
-	}()
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+	// lines locate cells, csv.Reader counts their columns in bytes
+	lines := bytes.Split(raw, []byte("\n"))
+	csvReader := csv.NewReader(bytes.NewReader(raw))
 
 	// index to language
 	index2lang := make(map[int]string)
+	// index to attribute
+	index2attr := make(map[int]string)
+	// indices of free-form comment columns, skipped
+	commentIndex := make(map[int]bool)
+	// current section header
+	var section string
+	// index of the module column
+	moduleIndex := -1
+	// index of the ignore column
+	ignoreIndex := -1
+	// lint rules ignored for the next key by a comment row
+	var ignoreNext []string
+	// flavor of a file like promo@huawei.csv, applied to all its columns
+	fileFlavor := model.FileFlavor(p)
 
 	tmp := &model.SourceFile{
 		Type:      model.SourceFileTypeCSV,
@@ -47,16 +60,42 @@ func LoadCSV(p string, collisionResolver CollisionResolver) (ret *model.SourceFi
 		var records []string
 		records, err = csvReader.Read()
 		if err == io.EOF {
+			err = nil
 			break
 		} else if err != nil {
 			return
 		}
-		if len(index2lang) == 0 {
+		if len(index2lang) == 0 && len(index2attr) == 0 && len(commentIndex) == 0 && moduleIndex < 0 && ignoreIndex < 0 {
 			for i, lang := range records {
-				if i > 0 && lang != "" {
-					index2lang[i] = lang
+				if i == 0 || lang == "" {
+					continue
+				}
+				if model.IsModuleColumn(lang) {
+					moduleIndex = i
+				} else if model.IsIgnoreColumn(lang) {
+					ignoreIndex = i
+				} else if attr, ok := model.AttributeColumn(lang); ok {
+					index2attr[i] = attr
+				} else if model.IsCommentColumn(lang) {
+					commentIndex[i] = true
+				} else {
+					base, flavor := model.SplitFlavor(lang)
+					if flavor == "" {
+						flavor = fileFlavor
+					}
+					index2lang[i] = model.FlavorLanguage(locale.Normalize(base), flavor)
 				}
 			}
+		} else if header, ok := sectionRow(records); ok {
+			if rules, ok := ignoreComment(header); ok {
+				ignoreNext, err = model.ParseIgnore(rules)
+				if err != nil {
+					err = fmt.Errorf("%v in comment %q", err, records[0])
+					return
+				}
+				continue
+			}
+			section = header
 		} else {
 			var strKey string
 			for i, str := range records {
@@ -71,6 +110,33 @@ func LoadCSV(p string, collisionResolver CollisionResolver) (ret *model.SourceFi
 						err = errors.New("empty key found in source file")
 						return
 					}
+					tmp.AddKey(strKey, section)
+					if len(ignoreNext) > 0 {
+						ignore(tmp, strKey, ignoreNext)
+						ignoreNext = nil
+					}
+				} else if i == moduleIndex {
+					if tmp.Modules == nil {
+						tmp.Modules = make(map[string]string)
+					}
+					name, _ := model.SplitResourceKey(strKey)
+					tmp.Modules[name] = strings.TrimSpace(str)
+				} else if i == ignoreIndex {
+					var rules []string
+					rules, err = model.ParseIgnore(str)
+					if err != nil {
+						err = fmt.Errorf("%v in ignore column of key %v", err, strKey)
+						return
+					}
+					ignore(tmp, strKey, rules)
+				} else if attr, ok := index2attr[i]; ok {
+					if tmp.Attributes == nil {
+						tmp.Attributes = make(map[string]map[string]string)
+					}
+					if tmp.Attributes[strKey] == nil {
+						tmp.Attributes[strKey] = make(map[string]string)
+					}
+					tmp.Attributes[strKey][attr] = strings.TrimSpace(str)
 				} else if lang, ok := index2lang[i]; ok {
 					if tmp.Languages[lang] == nil {
 						tmp.Languages[lang] = &model.LanguageKVS{
@@ -78,14 +144,34 @@ func LoadCSV(p string, collisionResolver CollisionResolver) (ret *model.SourceFi
 							KVS:      make(map[string]string),
 						}
 					}
+					kvs := tmp.Languages[lang].KVS
+					line, column := csvReader.FieldPos(i)
+					column = runeColumn(lines, line, column)
+					put := func(key, newValue string) {
+						tmp.SetPosition(lang, key, model.Position{Line: line, Column: column})
+						oldEntry, collision := kvs[key]
+						if collision && strings.Compare(oldEntry, newValue) != 0 {
+							if collisionResolver != nil {
+								newValue = collisionResolver(p, key, oldEntry, newValue)
+							}
+						}
+						kvs[key] = newValue
+					}
+
 					newValue := strings.TrimSpace(str)
-					oldEntry, collision := tmp.Languages[lang].KVS[strKey]
-					if collision && strings.Compare(oldEntry, newValue) != 0 {
-						if collisionResolver != nil {
-							newValue = collisionResolver(p, strKey, oldEntry, newValue)
+					forms, ok, icuErr := model.ParseICUPlural(newValue)
+					if icuErr != nil {
+						err = fmt.Errorf("%v in lang:%v of key %v", icuErr, lang, strKey)
+						return
+					}
+					if ok {
+						// expand icu plural cell into quantity keys
+						for quantity, form := range forms {
+							put(model.PluralKey(strKey, quantity), form)
 						}
+					} else {
+						put(strKey, newValue)
 					}
-					tmp.Languages[lang].KVS[strKey] = newValue
 				}
 			}
 		}
@@ -95,3 +181,47 @@ func LoadCSV(p string, collisionResolver CollisionResolver) (ret *model.SourceFi
 
 	return
 }
+
+// runeColumn converts the byte column of a cell on a 1-based line into a
+// column counted in unicode code points
+func runeColumn(lines [][]byte, line, column int) int {
+	if line < 1 || line > len(lines) || column < 1 || column > len(lines[line-1])+1 {
+		return column
+	}
+	return utf8.RuneCount(lines[line-1][:column-1]) + 1
+}
+
+// ignore records lint rules ignored for key
+func ignore(s *model.SourceFile, key string, rules []string) {
+	if s.Ignores == nil {
+		s.Ignores = make(model.Ignores)
+	}
+	s.Ignores.Add(key, rules...)
+}
+
+// ignoreComment returns the rules of a comment row like
+// "# lint-ignore: invalid-specifier"
+func ignoreComment(comment string) (rules string, ok bool) {
+	if !strings.HasPrefix(comment, model.IgnoreComment) {
+		return
+	}
+	rules = strings.TrimPrefix(comment, model.IgnoreComment)
+	if rules != "" && rules[0] != ':' && rules[0] != ' ' {
+		return "", false
+	}
+	return strings.TrimPrefix(strings.TrimSpace(rules), ":"), true
+}
+
+// sectionRow reports whether a row is a section header like "# Settings",
+// a key starting with # and no value
+func sectionRow(records []string) (section string, ok bool) {
+	if len(records) == 0 || !strings.HasPrefix(strings.TrimSpace(records[0]), "#") {
+		return
+	}
+	for _, v := range records[1:] {
+		if strings.TrimSpace(v) != "" {
+			return
+		}
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(records[0]), "#")), true
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+func TestLoadCSVPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strings.csv")
+	raw := "keys,en,zh\r\n" +
+		"title,Title,标题\r\n" +
+		"emoji,🙂 ok,\"多行\n文本\"\r\n" +
+		"\"中文键\",x,%s\r\n"
+	err := ioutil.WriteFile(path, []byte(raw), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := LoadCSV(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		lang string
+		key  string
+		want model.Position
+	}{
+		{lang: "en", key: "title", want: model.Position{Line: 2, Column: 7}},
+		{lang: "zh", key: "title", want: model.Position{Line: 2, Column: 13}},
+		{lang: "zh", key: "emoji", want: model.Position{Line: 3, Column: 12}},
+		{lang: "en", key: "中文键", want: model.Position{Line: 5, Column: 7}},
+		{lang: "zh", key: "中文键", want: model.Position{Line: 5, Column: 9}},
+	}
+	for _, tt := range tests {
+		got, ok := src.Position(tt.lang, tt.key)
+		if !ok || got != tt.want {
+			t.Errorf("Position(%v, %v) = %+v, %v, want %+v", tt.lang, tt.key, got, ok, tt.want)
+		}
+	}
+}
+
+func TestRuneColumn(t *testing.T) {
+	lines := [][]byte{[]byte("a,中文,🙂,b")}
+	tests := []struct {
+		column int
+		want   int
+	}{
+		{column: 1, want: 1},
+		{column: 3, want: 3},
+		{column: 10, want: 6},
+		{column: 15, want: 8},
+		// out of range positions are kept
+		{column: 40, want: 40},
+	}
+	for _, tt := range tests {
+		if got := runeColumn(lines, 1, tt.column); got != tt.want {
+			t.Errorf("runeColumn(%d) = %d, want %d", tt.column, got, tt.want)
+		}
+	}
+	if got := runeColumn(lines, 2, 5); got != 5 {
+		t.Errorf("runeColumn() on a missing line = %d, want 5", got)
+	}
+}
@@ -0,0 +1,268 @@
+// Package report describes the outcome of a run in a stable JSON schema for
+// scripts, fields are only ever added to it
+package report
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Schema is the version of the report schema, bumped on incompatible changes
+const Schema = 1
+
+// Stdout as report path writes the report to stdout
+const Stdout = "-"
+
+// Status is the outcome of a run
+type Status string
+
+const (
+	// StatusOK means the run finished
+	StatusOK Status = "ok"
+	// StatusLintFailed means the run stopped on lint issues
+	StatusLintFailed Status = "lint-failed"
+	// StatusOutOfSync means a check found files to change
+	StatusOutOfSync Status = "out-of-sync"
+	// StatusError means the run failed, see Errors
+	StatusError Status = "error"
+)
+
+// Resolution tells which value a collision resolved to
+type Resolution string
+
+const (
+	// ResolvedOld keeps the existing value
+	ResolvedOld Resolution = "old"
+	// ResolvedNew takes the value of the source
+	ResolvedNew Resolution = "new"
+	// ResolvedOther is a value picked among several sources
+	ResolvedOther Resolution = "other"
+)
+
+// Source is a source file loaded
+type Source struct {
+	Path      string   `json:"path"`
+	Languages []string `json:"languages"`
+	Keys      int      `json:"keys"`
+}
+
+// LintFinding is a lint issue of a source file
+type LintFinding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Language string `json:"language"`
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+}
+
+// MergeCollision is a key with different values across source files
+type MergeCollision struct {
+	Language string   `json:"language"`
+	Key      string   `json:"key"`
+	Files    []string `json:"files"`
+	Values   []string `json:"values"`
+	Value    string   `json:"value"`
+}
+
+// Collision is a key whose source value differs from the resource file
+type Collision struct {
+	File       string     `json:"file"`
+	Line       int        `json:"line"`
+	Key        string     `json:"key"`
+	Old        string     `json:"old"`
+	New        string     `json:"new"`
+	Resolution Resolution `json:"resolution"`
+}
+
+// File is a resource file appended to
+type File struct {
+	Path      string `json:"path"`
+	Language  string `json:"language"`
+	Created   bool   `json:"created"`
+	Added     int    `json:"added"`
+	Changed   int    `json:"changed"`
+	Unchanged int    `json:"unchanged"`
+	Skipped   int    `json:"skipped"`
+}
+
+// Step is the time spent on a step of the run
+type Step struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Schema           int               `json:"schema"`
+	Version          string            `json:"version"`
+	Command          string            `json:"command"`
+	Dry              bool              `json:"dry"`
+	Status           Status            `json:"status"`
+	Errors           []string          `json:"errors"`
+	Warnings         []string          `json:"warnings"`
+	StartedAt        time.Time         `json:"started_at"`
+	DurationMS       int64             `json:"duration_ms"`
+	Timing           []*Step           `json:"timing"`
+	Sources          []*Source         `json:"sources"`
+	Lint             []*LintFinding    `json:"lint"`
+	LintSuppressed   int               `json:"lint_suppressed"`
+	MergeCollisions  []*MergeCollision `json:"merge_collisions"`
+	Collisions       []*Collision      `json:"collisions"`
+	Files            []*File           `json:"files"`
+	SkippedLanguages []string          `json:"skipped_languages"`
+
+	mu        sync.Mutex
+	step      *Step
+	stepStart time.Time
+}
+
+// New returns an empty report of a run started now
+func New(version, command string) *Report {
+	return &Report{
+		Schema:           Schema,
+		Version:          version,
+		Command:          command,
+		Errors:           []string{},
+		Warnings:         []string{},
+		StartedAt:        time.Now(),
+		Timing:           []*Step{},
+		Sources:          []*Source{},
+		Lint:             []*LintFinding{},
+		MergeCollisions:  []*MergeCollision{},
+		Collisions:       []*Collision{},
+		Files:            []*File{},
+		SkippedLanguages: []string{},
+	}
+}
+
+// Begin ends the current step and starts timing the next one, the methods
+// of a nil report do nothing
+func (r *Report) Begin(step string) {
+	if r == nil {
+		return
+	}
+	r.endStep()
+	r.step = &Step{Name: step}
+	r.stepStart = time.Now()
+	r.Timing = append(r.Timing, r.step)
+}
+
+func (r *Report) endStep() {
+	if r.step != nil {
+		r.step.DurationMS = time.Since(r.stepStart).Milliseconds()
+		r.step = nil
+	}
+}
+
+// AddCollision records a collision with the resource file and the value it
+// resolved to
+func (r *Report) AddCollision(file string, line int, key, old, newer, value string) {
+	if r == nil {
+		return
+	}
+	resolution := ResolvedOther
+	switch value {
+	case newer:
+		resolution = ResolvedNew
+	case old:
+		resolution = ResolvedOld
+	}
+	r.Collisions = append(r.Collisions, &Collision{
+		File:       file,
+		Line:       line,
+		Key:        key,
+		Old:        old,
+		New:        newer,
+		Resolution: resolution,
+	})
+}
+
+// AddSource records a source file loaded
+func (r *Report) AddSource(v *Source) {
+	if r != nil {
+		r.Sources = append(r.Sources, v)
+	}
+}
+
+// AddLint records a lint finding
+func (r *Report) AddLint(v *LintFinding) {
+	if r != nil {
+		r.Lint = append(r.Lint, v)
+	}
+}
+
+// AddSuppressed counts lint findings suppressed by ignores or the baseline
+func (r *Report) AddSuppressed(n int) {
+	if r != nil {
+		r.LintSuppressed += n
+	}
+}
+
+// AddMergeCollision records a collision between source files
+func (r *Report) AddMergeCollision(v *MergeCollision) {
+	if r != nil {
+		r.MergeCollisions = append(r.MergeCollisions, v)
+	}
+}
+
+// AddFile records a resource file appended to
+func (r *Report) AddFile(v *File) {
+	if r != nil {
+		r.Files = append(r.Files, v)
+	}
+}
+
+// Finish ends the run with status
+func (r *Report) Finish(status Status) {
+	if r == nil {
+		return
+	}
+	r.endStep()
+	r.Status = status
+	r.DurationMS = time.Since(r.StartedAt).Milliseconds()
+}
+
+// Write writes the report to path, or to stdout if path is Stdout
+func (r *Report) Write(path string) error {
+	if r == nil {
+		return nil
+	}
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+	if path == Stdout {
+		_, err = os.Stdout.Write(raw)
+		return err
+	}
+	return ioutil.WriteFile(path, raw, 0644)
+}
+
+// Levels implements logrus.Hook
+func (r *Report) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+// Fire implements logrus.Hook, errors and warnings logged are recorded
+func (r *Report) Fire(entry *logrus.Entry) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry.Level == logrus.WarnLevel {
+		r.Warnings = append(r.Warnings, entry.Message)
+	} else {
+		r.Errors = append(r.Errors, entry.Message)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// TestSchema pins the JSON of a report, scripts rely on it: fields are only
+// ever added, a change to an existing one bumps Schema
+func TestSchema(t *testing.T) {
+	r := New("1.0.0", "i18n append --report report.json")
+	r.StartedAt = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	r.Dry = true
+	r.Begin("load")
+	r.AddSource(&Source{Path: "/repo/i18n/strings.csv", Languages: []string{"en", "zh-TW"}, Keys: 12})
+	r.Begin("lint")
+	r.AddLint(&LintFinding{
+		File:     "/repo/i18n/strings.csv",
+		Line:     3,
+		Column:   9,
+		Rule:     "placeholder-missing",
+		Severity: "error",
+		Language: "zh-TW",
+		Key:      "greeting",
+		Message:  `"%1$s" of lang:en is missing in lang:zh-TW`,
+	})
+	r.AddSuppressed(2)
+	r.Begin("merge")
+	r.AddMergeCollision(&MergeCollision{
+		Language: "en",
+		Key:      "title",
+		Files:    []string{"/repo/i18n/a.csv", "/repo/i18n/b.csv"},
+		Values:   []string{"Title", "Heading"},
+		Value:    "Heading",
+	})
+	r.Begin("append")
+	r.AddCollision("/repo/app/src/main/res/values/strings.xml", 4, "ok", "OK", "Okay", "Okay")
+	r.AddCollision("/repo/app/src/main/res/values/strings.xml", 5, "cancel", "Cancel", "Abort", "Cancel")
+	r.AddCollision("/repo/app/src/main/res/values/strings.xml", 6, "close", "Close", "Quit", "Exit")
+	r.AddFile(&File{Path: "/repo/app/src/main/res/values/strings.xml", Language: "en", Added: 3, Changed: 1, Unchanged: 7, Skipped: 1})
+	r.AddFile(&File{Path: "/repo/app/src/main/res/values-zh-rTW/strings.xml", Language: "zh-TW", Created: true, Added: 12})
+	r.SkippedLanguages = append(r.SkippedLanguages, "fr")
+	for _, level := range []logrus.Level{logrus.WarnLevel, logrus.ErrorLevel} {
+		if err := r.Fire(&logrus.Entry{Level: level, Message: level.String() + " logged"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Finish(StatusOK)
+
+	// durations vary between runs
+	r.DurationMS = 1500
+	for i, step := range r.Timing {
+		step.DurationMS = int64(i * 100)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := r.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "report.golden")
+	if *update {
+		if err = ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("report =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	r := New("1.0.0", "i18n check")
+	r.StartedAt = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	r.Finish(StatusError)
+	r.DurationMS = 0
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := r.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lists are empty, never null
+	want := `{
+  "schema": 1,
+  "version": "1.0.0",
+  "command": "i18n check",
+  "dry": false,
+  "status": "error",
+  "errors": [],
+  "warnings": [],
+  "started_at": "2021-06-01T12:00:00Z",
+  "duration_ms": 0,
+  "timing": [],
+  "sources": [],
+  "lint": [],
+  "lint_suppressed": 0,
+  "merge_collisions": [],
+  "collisions": [],
+  "files": [],
+  "skipped_languages": []
+}
+`
+	if string(got) != want {
+		t.Errorf("report =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNil(t *testing.T) {
+	var r *Report
+	r.Begin("load")
+	r.AddSource(&Source{})
+	r.AddLint(&LintFinding{})
+	r.AddSuppressed(1)
+	r.AddMergeCollision(&MergeCollision{})
+	r.AddCollision("strings.xml", 1, "a", "A", "B", "B")
+	r.AddFile(&File{})
+	r.Finish(StatusOK)
+	if err := r.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "failed"}); err != nil {
+		t.Errorf("Fire() err:%v", err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := r.Write(path); err != nil {
+		t.Errorf("Write() err:%v", err)
+	}
+	if _, err := ioutil.ReadFile(path); !os.IsNotExist(err) {
+		t.Errorf("nil report wrote %v", path)
+	}
+}
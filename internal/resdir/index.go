@@ -0,0 +1,127 @@
+package resdir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/master-g/i18n/internal/model"
+	"github.com/master-g/i18n/internal/resxml"
+)
+
+// Location of a resource definition
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+func (l Location) String() string {
+	if l.Line <= 0 {
+		return l.File
+	}
+	return fmt.Sprintf("%v:%d", l.File, l.Line)
+}
+
+// ResourceID identifies a resource within a values folder, e.g. string/app_name
+func ResourceID(tag, name string) string {
+	return tag + "/" + name
+}
+
+// KeyResource returns the name and tag of the resource a source key is
+// written to, e.g. items[one] -> items, plurals
+func KeyResource(key string) (name, tag string) {
+	name, selector := model.SplitResourceKey(key)
+	if selector != "" && model.IsPluralQuantity(selector) {
+		return name, resxml.TagPlurals
+	}
+	if _, ok := model.ArrayIndex(selector); ok {
+		return name, resxml.TagStringArray
+	}
+	return key, resxml.TagString
+}
+
+// IsStringResource reports whether tag is a string, plurals or string-array
+func IsStringResource(tag string) bool {
+	return tag == resxml.TagString || tag == resxml.TagPlurals || tag == resxml.TagStringArray
+}
+
+// Index maps resource ids of a values folder to their definitions
+type Index map[string][]Location
+
+// File is a parsed resource xml file
+type File struct {
+	Path string
+	Doc  *resxml.Document
+}
+
+// LoadFolder parses all xml files in a values folder, sorted by name, files
+// in exclude and empty files are skipped
+func LoadFolder(folder string, exclude ...string) (loaded []*File, err error) {
+	var files []string
+	files, err = filepath.Glob(filepath.Join(folder, "*.xml"))
+	if err != nil {
+		return
+	}
+	sort.Strings(files)
+
+	skip := make(map[string]bool)
+	for _, v := range exclude {
+		skip[filepath.Clean(v)] = true
+	}
+
+	for _, file := range files {
+		if skip[filepath.Clean(file)] {
+			continue
+		}
+		var raw []byte
+		raw, err = ioutil.ReadFile(file)
+		if err != nil {
+			return
+		}
+		if len(strings.TrimSpace(string(raw))) == 0 {
+			continue
+		}
+		var doc *resxml.Document
+		doc, err = resxml.Parse(raw)
+		if err != nil {
+			err = fmt.Errorf("cannot parse %v, err:%v", file, err)
+			return
+		}
+		loaded = append(loaded, &File{Path: file, Doc: doc})
+	}
+
+	return
+}
+
+// IndexFolder indexes the resources of all xml files in a values folder,
+// files in exclude are skipped
+func IndexFolder(folder string, exclude ...string) (idx Index, err error) {
+	var files []*File
+	files, err = LoadFolder(folder, exclude...)
+	if err != nil {
+		return
+	}
+	idx = make(Index)
+	for _, f := range files {
+		idx.Add(f.Path, f.Doc)
+	}
+	return
+}
+
+// Add indexes the resources of a parsed file
+func (idx Index) Add(file string, doc *resxml.Document) {
+	for _, e := range doc.Elements() {
+		if e.Name == "" {
+			continue
+		}
+		id := ResourceID(e.Tag, e.Name)
+		idx[id] = append(idx[id], Location{File: file, Line: e.Line})
+	}
+}
+
+// Lookup returns the definitions of a resource
+func (idx Index) Lookup(tag, name string) []Location {
+	return idx[ResourceID(tag, name)]
+}
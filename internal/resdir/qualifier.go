@@ -0,0 +1,237 @@
+// Package resdir parses android resource directories and their folder names
+package resdir
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/master-g/i18n/pkg/locale"
+)
+
+// Kind of a configuration qualifier, in the order android requires them
+type Kind int
+
+const (
+	KindMCC Kind = iota
+	KindMNC
+	KindLocale
+	KindLayoutDirection
+	KindSmallestWidth
+	KindAvailableWidth
+	KindAvailableHeight
+	KindScreenSize
+	KindScreenAspect
+	KindRoundScreen
+	KindColorGamut
+	KindHDR
+	KindOrientation
+	KindUIMode
+	KindNightMode
+	KindDensity
+	KindTouchscreen
+	KindKeyboard
+	KindInputMethod
+	KindNavigationKeys
+	KindNavigation
+	KindVersion
+)
+
+var kindNames = map[Kind]string{
+	KindMCC:             "mcc",
+	KindMNC:             "mnc",
+	KindLocale:          "locale",
+	KindLayoutDirection: "layout direction",
+	KindSmallestWidth:   "smallest width",
+	KindAvailableWidth:  "available width",
+	KindAvailableHeight: "available height",
+	KindScreenSize:      "screen size",
+	KindScreenAspect:    "screen aspect",
+	KindRoundScreen:     "round screen",
+	KindColorGamut:      "color gamut",
+	KindHDR:             "hdr",
+	KindOrientation:     "orientation",
+	KindUIMode:          "ui mode",
+	KindNightMode:       "night mode",
+	KindDensity:         "density",
+	KindTouchscreen:     "touchscreen",
+	KindKeyboard:        "keyboard",
+	KindInputMethod:     "input method",
+	KindNavigationKeys:  "navigation keys",
+	KindNavigation:      "navigation",
+	KindVersion:         "version",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+// matchers of the single token qualifiers, locale is handled separately
+var qualifierMatchers = []struct {
+	kind  Kind
+	match func(s string) bool
+}{
+	{KindMCC, regexMatcher(`^mcc\d{3}$`)},
+	{KindMNC, regexMatcher(`^mnc\d{1,3}$`)},
+	{KindLayoutDirection, oneOf("ldrtl", "ldltr")},
+	{KindSmallestWidth, regexMatcher(`^sw\d+dp$`)},
+	{KindAvailableWidth, regexMatcher(`^w\d+dp$`)},
+	{KindAvailableHeight, regexMatcher(`^h\d+dp$`)},
+	{KindScreenSize, oneOf("small", "normal", "large", "xlarge")},
+	{KindScreenAspect, oneOf("long", "notlong")},
+	{KindRoundScreen, oneOf("round", "notround")},
+	{KindColorGamut, oneOf("widecg", "nowidecg")},
+	{KindHDR, oneOf("highdr", "lowdr")},
+	{KindOrientation, oneOf("port", "land")},
+	{KindUIMode, oneOf("car", "desk", "television", "appliance", "watch", "vrheadset")},
+	{KindNightMode, oneOf("night", "notnight")},
+	{KindDensity, regexMatcher(`^(ldpi|mdpi|hdpi|xhdpi|xxhdpi|xxxhdpi|nodpi|tvdpi|anydpi|\d+dpi)$`)},
+	{KindTouchscreen, oneOf("notouch", "finger")},
+	{KindKeyboard, oneOf("keysexposed", "keyshidden", "keyssoft")},
+	{KindInputMethod, oneOf("nokeys", "qwerty", "12key")},
+	{KindNavigationKeys, oneOf("navexposed", "navhidden")},
+	{KindNavigation, oneOf("nonav", "dpad", "trackball", "wheel")},
+	{KindVersion, regexMatcher(`^v\d+$`)},
+}
+
+func regexMatcher(expr string) func(s string) bool {
+	re := regexp.MustCompile(expr)
+	return re.MatchString
+}
+
+func oneOf(values ...string) func(s string) bool {
+	return func(s string) bool {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var androidRegionRegex = regexp.MustCompile(`^r[A-Za-z]{2}$`)
+
+// Qualifier is a single configuration qualifier of a folder name
+type Qualifier struct {
+	Kind  Kind
+	Value string
+}
+
+// Folder is a parsed resource folder name like values-zh-rTW-night
+type Folder struct {
+	// Type is the resource type, e.g. values
+	Type string
+	// Locale is the locale qualifier, zero if there is none
+	Locale locale.Locale
+	// Qualifiers are all qualifiers in folder name order, including the locale
+	Qualifiers []Qualifier
+}
+
+// ParseFolder parses a resource folder name, qualifiers must follow the
+// order android requires
+func ParseFolder(name string) (f *Folder, err error) {
+	tokens := strings.Split(name, "-")
+	f = &Folder{Type: tokens[0]}
+	if f.Type == "" {
+		return nil, fmt.Errorf("invalid resource folder %q", name)
+	}
+
+	last := Kind(-1)
+	for i := 1; i < len(tokens); i++ {
+		token := tokens[i]
+		q := Qualifier{Kind: -1, Value: token}
+
+		if last < KindLocale {
+			if strings.HasPrefix(token, "b+") {
+				q.Kind = KindLocale
+			} else if len(token) == 2 || len(token) == 3 {
+				if _, err := locale.Parse(token); err == nil && !isQualifierToken(token) {
+					q.Kind = KindLocale
+					if i+1 < len(tokens) && androidRegionRegex.MatchString(tokens[i+1]) {
+						i++
+						q.Value = token + "-" + tokens[i]
+					}
+				}
+			}
+			if q.Kind == KindLocale {
+				f.Locale, err = locale.Parse(q.Value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid locale in resource folder %q, err:%v", name, err)
+				}
+			}
+		}
+
+		if q.Kind < 0 {
+			lower := strings.ToLower(token)
+			for _, m := range qualifierMatchers {
+				if m.kind > last && m.match(lower) {
+					q.Kind = m.kind
+					q.Value = lower
+					break
+				}
+			}
+		}
+
+		if q.Kind < 0 {
+			return nil, fmt.Errorf("invalid or misplaced qualifier %q in resource folder %q", token, name)
+		}
+		last = q.Kind
+		f.Qualifiers = append(f.Qualifiers, q)
+	}
+
+	return
+}
+
+// isQualifierToken reports whether a short token is a non locale qualifier,
+// e.g. car
+func isQualifierToken(token string) bool {
+	lower := strings.ToLower(token)
+	for _, m := range qualifierMatchers {
+		if m.match(lower) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLocale reports whether the folder has a locale qualifier
+func (f *Folder) HasLocale() bool {
+	return !f.Locale.IsZero()
+}
+
+// QualifierSet returns the non locale qualifiers joined by '-', e.g.
+// night-v26, empty for folders with default configuration
+func (f *Folder) QualifierSet() string {
+	var parts []string
+	for _, q := range f.Qualifiers {
+		if q.Kind != KindLocale {
+			parts = append(parts, q.Value)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// IsDefault reports whether the folder has no qualifiers other than locale
+func (f *Folder) IsDefault() bool {
+	return f.QualifierSet() == ""
+}
+
+// NormalizeQualifierSet validates a set of non locale qualifiers like
+// sw600dp-land and returns it lowercase
+func NormalizeQualifierSet(set string) (string, error) {
+	if set == "" {
+		return "", nil
+	}
+	f, err := ParseFolder("values-" + set)
+	if err != nil {
+		return "", err
+	}
+	if f.HasLocale() {
+		return "", fmt.Errorf("qualifier set %q must not hold a locale", set)
+	}
+	return f.QualifierSet(), nil
+}
@@ -0,0 +1,100 @@
+package resdir
+
+import (
+	"testing"
+)
+
+func TestParseFolder(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  string
+		set     string
+		kinds   []Kind
+		wantErr bool
+	}{
+		{name: "values"},
+		{name: "values-fr", locale: "fr", kinds: []Kind{KindLocale}},
+		{name: "values-zh-rTW", locale: "zh-TW", kinds: []Kind{KindLocale}},
+		{name: "values-fil", locale: "fil", kinds: []Kind{KindLocale}},
+		{name: "values-iw", locale: "he", kinds: []Kind{KindLocale}},
+		{name: "values-in-rID", locale: "id-ID", kinds: []Kind{KindLocale}},
+		{name: "values-ji", locale: "yi", kinds: []Kind{KindLocale}},
+		{name: "values-b+sr+Latn", locale: "sr-Latn", kinds: []Kind{KindLocale}},
+		{name: "values-b+es+419-night", locale: "es-419", set: "night", kinds: []Kind{KindLocale, KindNightMode}},
+		{name: "values-mcc310-mnc004-en-rUS", locale: "en-US", set: "mcc310-mnc004", kinds: []Kind{KindMCC, KindMNC, KindLocale}},
+		{name: "values-en-ldrtl-sw600dp-land", locale: "en", set: "ldrtl-sw600dp-land", kinds: []Kind{KindLocale, KindLayoutDirection, KindSmallestWidth, KindOrientation}},
+		{name: "values-night-v26", set: "night-v26", kinds: []Kind{KindNightMode, KindVersion}},
+		{name: "values-LAND-xxhdpi", set: "land-xxhdpi", kinds: []Kind{KindOrientation, KindDensity}},
+		{name: "values-car", set: "car", kinds: []Kind{KindUIMode}},
+		{name: "values-de-car", locale: "de", set: "car", kinds: []Kind{KindLocale, KindUIMode}},
+		{name: "values-v26-night", wantErr: true},
+		{name: "values-night-en", wantErr: true},
+		{name: "values-en-fr", wantErr: true},
+		{name: "values-land-port", wantErr: true},
+		{name: "values-b+", wantErr: true},
+		{name: "values-foobar", wantErr: true},
+		{name: "values-", wantErr: true},
+		{name: "-night", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFolder(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseFolder(%q) = %+v, want error", tt.name, f)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFolder(%q) err:%v", tt.name, err)
+			}
+			if f.Type != "values" {
+				t.Errorf("ParseFolder(%q).Type = %v, want values", tt.name, f.Type)
+			}
+			if got := f.Locale.String(); got != tt.locale {
+				t.Errorf("ParseFolder(%q).Locale = %v, want %v", tt.name, got, tt.locale)
+			}
+			if f.HasLocale() != (tt.locale != "") {
+				t.Errorf("ParseFolder(%q).HasLocale() = %v", tt.name, f.HasLocale())
+			}
+			if got := f.QualifierSet(); got != tt.set {
+				t.Errorf("ParseFolder(%q).QualifierSet() = %v, want %v", tt.name, got, tt.set)
+			}
+			if f.IsDefault() != (tt.set == "") {
+				t.Errorf("ParseFolder(%q).IsDefault() = %v", tt.name, f.IsDefault())
+			}
+			if len(f.Qualifiers) != len(tt.kinds) {
+				t.Fatalf("ParseFolder(%q) has %d qualifiers, want %d", tt.name, len(f.Qualifiers), len(tt.kinds))
+			}
+			for i, q := range f.Qualifiers {
+				if q.Kind != tt.kinds[i] {
+					t.Errorf("ParseFolder(%q) qualifier %v is %v, want %v", tt.name, q.Value, q.Kind, tt.kinds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeQualifierSet(t *testing.T) {
+	tests := []struct {
+		set     string
+		want    string
+		wantErr bool
+	}{
+		{set: "", want: ""},
+		{set: "Night-V26", want: "night-v26"},
+		{set: "sw600dp-land", want: "sw600dp-land"},
+		{set: "en", wantErr: true},
+		{set: "v26-night", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeQualifierSet(tt.set)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeQualifierSet(%q) err:%v, want error %v", tt.set, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeQualifierSet(%q) = %v, want %v", tt.set, got, tt.want)
+		}
+	}
+}
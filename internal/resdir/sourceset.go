@@ -0,0 +1,36 @@
+package resdir
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// MainSourceSet is the source set shared by all build variants
+const MainSourceSet = "main"
+
+// BuildDirs are folders holding build output or tooling state, never sources
+var BuildDirs = []string{"build", ".gradle", ".git", ".idea", ".cxx", "node_modules"}
+
+// SourceSet returns the source set of a res directory in the gradle layout
+// <module>/src/<set>/res, ok is false for other layouts
+func SourceSet(resDir string) (set string, ok bool) {
+	resDir = filepath.Clean(resDir)
+	if filepath.Base(resDir) != "res" {
+		return "", false
+	}
+	setDir := filepath.Dir(resDir)
+	if filepath.Base(filepath.Dir(setDir)) != "src" {
+		return "", false
+	}
+	return filepath.Base(setDir), true
+}
+
+// SourceSetRes returns the res directory of another source set of the same
+// module, e.g. app/src/main/res -> app/src/huawei/res
+func SourceSetRes(resDir, set string) (string, error) {
+	if _, ok := SourceSet(resDir); !ok {
+		return "", fmt.Errorf("%v is not in the <module>/src/<source set>/res layout", resDir)
+	}
+	src := filepath.Dir(filepath.Dir(filepath.Clean(resDir)))
+	return filepath.Join(src, set, "res"), nil
+}
@@ -0,0 +1,55 @@
+package resdir
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceSet(t *testing.T) {
+	tests := []struct {
+		res string
+		set string
+		ok  bool
+	}{
+		{res: "app/src/main/res", set: "main", ok: true},
+		{res: "app/src/huawei/res/", set: "huawei", ok: true},
+		{res: "/repo/feature/src/huaweiRelease/res", set: "huaweiRelease", ok: true},
+		{res: "src/debug/res", set: "debug", ok: true},
+		{res: "app/res"},
+		{res: "app/src/main/resources"},
+		{res: "app/main/res"},
+		{res: "res"},
+	}
+	for _, tt := range tests {
+		set, ok := SourceSet(filepath.FromSlash(tt.res))
+		if set != tt.set || ok != tt.ok {
+			t.Errorf("SourceSet(%v) = %q, %v, want %q, %v", tt.res, set, ok, tt.set, tt.ok)
+		}
+	}
+}
+
+func TestSourceSetRes(t *testing.T) {
+	tests := []struct {
+		res     string
+		set     string
+		want    string
+		wantErr bool
+	}{
+		{res: "app/src/main/res", set: "huawei", want: "app/src/huawei/res"},
+		{res: "app/src/huawei/res/", set: "main", want: "app/src/main/res"},
+		{res: "/repo/app/src/main/res", set: "debug", want: "/repo/app/src/debug/res"},
+		{res: "app/res", set: "huawei", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := SourceSetRes(filepath.FromSlash(tt.res), tt.set)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("SourceSetRes(%v, %v) = %v, want an error", tt.res, tt.set, got)
+			}
+			continue
+		}
+		if err != nil || got != filepath.FromSlash(tt.want) {
+			t.Errorf("SourceSetRes(%v, %v) = %v, err:%v, want %v", tt.res, tt.set, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package resxml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markers of the region owned by i18n
+const (
+	BlockBegin = "i18n:begin"
+	BlockEnd   = "i18n:end"
+)
+
+// Block is the managed region between <!-- i18n:begin --> and
+// <!-- i18n:end --> comments
+type Block struct {
+	Begin *Comment
+	End   *Comment
+}
+
+// Contains reports whether the element lies inside the block
+func (b *Block) Contains(e *Element) bool {
+	return e.Start >= b.Begin.End && e.End <= b.End.Start
+}
+
+// ManagedBlock returns the managed block of the document, nil if there is
+// none, markers must appear once and in order
+func (doc *Document) ManagedBlock() (b *Block, err error) {
+	var begin, end *Comment
+	for _, c := range doc.comments {
+		switch c.Text {
+		case BlockBegin:
+			if begin != nil {
+				return nil, fmt.Errorf("duplicate <!-- %v --> at line %d", BlockBegin, c.Line)
+			}
+			begin = c
+		case BlockEnd:
+			if end != nil {
+				return nil, fmt.Errorf("duplicate <!-- %v --> at line %d", BlockEnd, c.Line)
+			}
+			end = c
+		}
+	}
+	if begin == nil && end == nil {
+		return nil, nil
+	}
+	if begin == nil {
+		return nil, fmt.Errorf("<!-- %v --> at line %d has no <!-- %v -->", BlockEnd, end.Line, BlockBegin)
+	}
+	if end == nil {
+		return nil, fmt.Errorf("<!-- %v --> at line %d has no <!-- %v -->", BlockBegin, begin.Line, BlockEnd)
+	}
+	if end.Start < begin.End {
+		return nil, fmt.Errorf("<!-- %v --> at line %d comes before <!-- %v -->", BlockEnd, end.Line, BlockBegin)
+	}
+	return &Block{Begin: begin, End: end}, nil
+}
+
+// ReplaceBlock replaces everything between the markers of the block with
+// each text on its own line, nothing is changed if the content is the same
+func (doc *Document) ReplaceBlock(b *Block, texts []string) {
+	sb := &strings.Builder{}
+	for _, text := range texts {
+		sb.WriteString(doc.indent + text + doc.newline)
+	}
+
+	start := b.Begin.End
+	end := lineStartAt(doc.raw, b.End.Start)
+	if end > start && strings.HasPrefix(string(doc.raw[start:end]), doc.newline) {
+		// markers on their own lines
+		start += len(doc.newline)
+	} else {
+		sb.Reset()
+		sb.WriteString(doc.newline)
+		for _, text := range texts {
+			sb.WriteString(doc.indent + text + doc.newline)
+		}
+		sb.WriteString(doc.indent)
+		start, end = b.Begin.End, b.End.Start
+	}
+
+	if string(doc.raw[start:end]) == sb.String() {
+		return
+	}
+	doc.edits = append(doc.edits, &edit{start: start, end: end, text: sb.String()})
+}
+
+// AppendBlock appends a new managed block holding texts before </resources>
+func (doc *Document) AppendBlock(texts []string, separate bool) {
+	lines := make([]string, 0, len(texts)+2)
+	lines = append(lines, "<!-- "+BlockBegin+" -->")
+	lines = append(lines, texts...)
+	lines = append(lines, "<!-- "+BlockEnd+" -->")
+	doc.AppendLines(lines, separate)
+}
@@ -0,0 +1,181 @@
+package resxml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestManagedBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		found   bool
+		inside  string
+		wantErr string
+	}{
+		{
+			name: "none",
+			raw:  "<resources>\n    <!-- notes -->\n    <string name=\"a\">A</string>\n</resources>\n",
+		},
+		{
+			name:   "block",
+			raw:    "<resources>\n    <string name=\"a\">A</string>\n    <!-- i18n:begin -->\n    <string name=\"b\">B</string>\n    <!-- i18n:end -->\n</resources>\n",
+			found:  true,
+			inside: "b",
+		},
+		{
+			name:   "markers with spaces",
+			raw:    "<resources>\n    <!--   i18n:begin   -->\n    <string name=\"b\">B</string>\n    <!--i18n:end-->\n</resources>\n",
+			found:  true,
+			inside: "b",
+		},
+		{
+			name:  "empty block",
+			raw:   "<resources>\n    <!-- i18n:begin --><!-- i18n:end -->\n    <string name=\"a\">A</string>\n</resources>\n",
+			found: true,
+		},
+		{
+			name:    "duplicate begin",
+			raw:     "<resources>\n    <!-- i18n:begin -->\n    <!-- i18n:begin -->\n    <!-- i18n:end -->\n</resources>\n",
+			wantErr: "duplicate <!-- i18n:begin --> at line 3",
+		},
+		{
+			name:    "duplicate end",
+			raw:     "<resources>\n    <!-- i18n:begin -->\n    <!-- i18n:end -->\n    <!-- i18n:end -->\n</resources>\n",
+			wantErr: "duplicate <!-- i18n:end --> at line 4",
+		},
+		{
+			name:    "no end",
+			raw:     "<resources>\n    <!-- i18n:begin -->\n</resources>\n",
+			wantErr: "has no <!-- i18n:end -->",
+		},
+		{
+			name:    "no begin",
+			raw:     "<resources>\n    <!-- i18n:end -->\n</resources>\n",
+			wantErr: "has no <!-- i18n:begin -->",
+		},
+		{
+			name:    "reversed",
+			raw:     "<resources>\n    <!-- i18n:end -->\n    <!-- i18n:begin -->\n</resources>\n",
+			wantErr: "comes before",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := doc.ManagedBlock()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ManagedBlock() err:%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (b != nil) != tt.found {
+				t.Fatalf("ManagedBlock() = %v, want found %v", b, tt.found)
+			}
+			if b == nil {
+				return
+			}
+			var inside []string
+			for _, e := range doc.Elements() {
+				if b.Contains(e) {
+					inside = append(inside, e.Name)
+				}
+			}
+			if got := strings.Join(inside, ","); got != tt.inside {
+				t.Errorf("block contains %v, want %v", got, tt.inside)
+			}
+		})
+	}
+}
+
+func TestReplaceBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		texts []string
+		want  string
+	}{
+		{
+			name:  "markers on their own lines",
+			raw:   "<resources>\n    <string name=\"a\">A</string>\n    <!-- i18n:begin -->\n    <string name=\"b\">B</string>\n    <string name=\"old\">Old</string>\n    <!-- i18n:end -->\n</resources>\n",
+			texts: []string{`<string name="b">B2</string>`, `<string name="c">C</string>`},
+			want:  "<resources>\n    <string name=\"a\">A</string>\n    <!-- i18n:begin -->\n    <string name=\"b\">B2</string>\n    <string name=\"c\">C</string>\n    <!-- i18n:end -->\n</resources>\n",
+		},
+		{
+			name:  "markers on one line",
+			raw:   "<resources>\n    <!-- i18n:begin --><!-- i18n:end -->\n</resources>\n",
+			texts: []string{`<string name="b">B</string>`},
+			want:  "<resources>\n    <!-- i18n:begin -->\n    <string name=\"b\">B</string>\n    <!-- i18n:end -->\n</resources>\n",
+		},
+		{
+			name:  "emptied",
+			raw:   "<resources>\n    <!-- i18n:begin -->\n    <string name=\"b\">B</string>\n    <!-- i18n:end -->\n</resources>\n",
+			texts: nil,
+			want:  "<resources>\n    <!-- i18n:begin -->\n    <!-- i18n:end -->\n</resources>\n",
+		},
+		{
+			name:  "crlf and tabs",
+			raw:   "<resources>\r\n\t<!-- i18n:begin -->\r\n\t<string name=\"b\">B</string>\r\n\t<!-- i18n:end -->\r\n</resources>\r\n",
+			texts: []string{`<string name="b">B2</string>`},
+			want:  "<resources>\r\n\t<!-- i18n:begin -->\r\n\t<string name=\"b\">B2</string>\r\n\t<!-- i18n:end -->\r\n</resources>\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := doc.ManagedBlock()
+			if err != nil || b == nil {
+				t.Fatalf("ManagedBlock() = %v, err:%v", b, err)
+			}
+			doc.ReplaceBlock(b, tt.texts)
+			got, err := doc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReplaceBlock() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	// the same content is no change
+	raw := "<resources>\n    <!-- i18n:begin -->\n    <string name=\"b\">B</string>\n    <!-- i18n:end -->\n</resources>\n"
+	doc, err := Parse([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := doc.ManagedBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.ReplaceBlock(b, []string{`<string name="b">B</string>`})
+	if doc.Changed() {
+		t.Error("ReplaceBlock() with the same content changes the document")
+	}
+}
+
+func TestAppendBlock(t *testing.T) {
+	doc, err := Parse([]byte("<resources>\n    <string name=\"a\">A</string>\n</resources>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.AppendBlock([]string{`<string name="b">B</string>`}, true)
+	got, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<resources>\n    <string name=\"a\">A</string>\n\n    <!-- i18n:begin -->\n    <string name=\"b\">B</string>\n    <!-- i18n:end -->\n</resources>\n"
+	if string(got) != want {
+		t.Errorf("AppendBlock() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,533 @@
+// Package resxml edits android resource xml files in place. Only the
+// elements being edited are rewritten, every other byte (comments, blank
+// lines, indentation, line endings, declarations) is kept as it was.
+package resxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const tagResources = "resources"
+
+// Skeleton is the content of a new, empty resource file
+const Skeleton = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+</resources>
+`
+
+// resource element tags with special handling
+const (
+	TagString      = "string"
+	TagPlurals     = "plurals"
+	TagStringArray = "string-array"
+	TagItem        = "item"
+)
+
+// Item is an <item> child of a plurals or array element
+type Item struct {
+	Attrs      []xml.Attr `json:"attrs,omitempty"`
+	Start      int        `json:"start"`
+	End        int        `json:"end"`
+	InnerStart int        `json:"inner_start"`
+	InnerEnd   int        `json:"inner_end"`
+	value      string
+}
+
+// Value returns the raw inner xml of the item
+func (i *Item) Value() string {
+	return i.value
+}
+
+// Attr returns the value of an attribute, e.g. quantity
+func (i *Item) Attr(name string) string {
+	return attrValue(i.Attrs, name)
+}
+
+// Element is a resource element directly under <resources>
+type Element struct {
+	Tag        string     `json:"tag"`
+	Name       string     `json:"name"`
+	Attrs      []xml.Attr `json:"attrs,omitempty"`
+	Items      []*Item    `json:"items,omitempty"`
+	Line       int        `json:"line"`
+	Start      int        `json:"start"`
+	End        int        `json:"end"`
+	InnerStart int        `json:"inner_start"`
+	InnerEnd   int        `json:"inner_end"`
+	value      string
+	closed     bool
+	// pending start tag and content of a self-closing element, both go in
+	// one edit
+	startTag string
+	inner    *string
+	edit     *edit
+}
+
+// SelfClosing reports whether the element is written like <string name="a"/>
+func (e *Element) SelfClosing() bool {
+	return e.closed
+}
+
+// Value returns the raw inner xml of the element
+func (e *Element) Value() string {
+	return e.value
+}
+
+// Attr returns the value of an attribute other than name
+func (e *Element) Attr(name string) string {
+	return attrValue(e.Attrs, name)
+}
+
+// Comment is a comment directly under <resources>
+type Comment struct {
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// Document is a parsed resource xml file
+type Document struct {
+	raw             []byte
+	elements        []*Element
+	comments        []*Comment
+	rootTagEnd      int
+	rootSelfClosing bool
+	rootAttrs       []xml.Attr
+	rootEnd         int
+	newline         string
+	indent          string
+	edits           []*edit
+}
+
+// Parse parses a resource xml file
+func Parse(raw []byte) (doc *Document, err error) {
+	tmp := &Document{
+		raw:     raw,
+		newline: "\n",
+		indent:  "    ",
+	}
+	if bytes.Contains(raw, []byte("\r\n")) {
+		tmp.newline = "\r\n"
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(raw))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	var stack []*Element
+	var item *Item
+	depth := 0
+	rootFound := false
+	indentFound := false
+	for {
+		start := int(d.InputOffset())
+		var tok xml.Token
+		tok, err = d.RawToken()
+		if err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = fmt.Errorf("invalid xml at line %d, err:%v", lineAt(raw, start), err)
+			return
+		}
+		offset := int(d.InputOffset())
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 1:
+				if t.Name.Local != tagResources {
+					err = fmt.Errorf("unexpected root element <%v>", qualifiedName(t.Name))
+					return
+				}
+				rootFound = true
+				tmp.rootTagEnd = offset
+				tmp.rootSelfClosing = bytes.HasSuffix(raw[:offset], []byte("/>"))
+				tmp.rootAttrs = t.Attr
+			case 2:
+				e := &Element{
+					Tag:        qualifiedName(t.Name),
+					Line:       lineAt(raw, start),
+					Start:      start,
+					InnerStart: offset,
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "" && attr.Name.Local == "name" {
+						e.Name = attr.Value
+					} else {
+						e.Attrs = append(e.Attrs, attr)
+					}
+				}
+				if !indentFound {
+					indentFound = true
+					tmp.indent = leadingIndent(raw, start)
+				}
+				stack = append(stack, e)
+			case 3:
+				if t.Name.Local == TagItem && len(stack) > 0 {
+					item = &Item{
+						Attrs:      t.Attr,
+						Start:      start,
+						InnerStart: offset,
+					}
+				}
+			}
+		case xml.Comment:
+			if depth == 1 {
+				tmp.comments = append(tmp.comments, &Comment{
+					Text:  strings.TrimSpace(string(t)),
+					Line:  lineAt(raw, start),
+					Start: start,
+					End:   offset,
+				})
+			}
+		case xml.EndElement:
+			switch depth {
+			case 1:
+				tmp.rootEnd = start
+				if tmp.rootSelfClosing {
+					tmp.rootEnd = offset
+				}
+			case 2:
+				e := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				e.End = offset
+				e.InnerEnd = start
+				if start == offset {
+					// self-closing
+					e.closed = true
+					e.InnerEnd = e.InnerStart
+				}
+				e.value = string(raw[e.InnerStart:e.InnerEnd])
+				tmp.elements = append(tmp.elements, e)
+			case 3:
+				if item != nil && len(stack) > 0 {
+					item.End = offset
+					item.InnerEnd = start
+					if item.InnerEnd < item.InnerStart {
+						item.InnerEnd = item.InnerStart
+					}
+					item.value = string(raw[item.InnerStart:item.InnerEnd])
+					e := stack[len(stack)-1]
+					e.Items = append(e.Items, item)
+					item = nil
+				}
+			}
+			depth--
+		}
+	}
+
+	if !rootFound {
+		err = errors.New("<resources> not found")
+		return
+	}
+	if depth != 0 {
+		err = errors.New("unexpected end of file, <resources> not closed")
+		return
+	}
+
+	doc = tmp
+
+	return
+}
+
+// Elements returns all resource elements in document order
+func (doc *Document) Elements() []*Element {
+	return doc.elements
+}
+
+// Comments returns all comments directly under <resources> in document order
+func (doc *Document) Comments() []*Comment {
+	return doc.comments
+}
+
+// Lookup returns the first element with tag and name
+func (doc *Document) Lookup(tag, name string) *Element {
+	for _, e := range doc.elements {
+		if e.Tag == tag && e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Newline returns the line ending used by the document
+func (doc *Document) Newline() string {
+	return doc.newline
+}
+
+// Indent returns the indentation of resource elements
+func (doc *Document) Indent() string {
+	return doc.indent
+}
+
+// RootAttr returns the value of an attribute of <resources>
+func (doc *Document) RootAttr(name string) string {
+	return attrValue(doc.rootAttrs, name)
+}
+
+// Raw returns the original content
+func (doc *Document) Raw() []byte {
+	return doc.raw
+}
+
+// Line returns the 1-based line number of a byte offset
+func (doc *Document) Line(offset int) int {
+	return lineAt(doc.raw, offset)
+}
+
+// Replace replaces the whole element with text
+func (doc *Document) Replace(e *Element, text string) {
+	doc.edits = append(doc.edits, &edit{start: e.Start, end: e.End, text: text})
+}
+
+// ReplaceStartTag replaces the start tag of the element, e.g. to change attributes
+func (doc *Document) ReplaceStartTag(e *Element, text string) {
+	if e.closed {
+		e.startTag = strings.TrimSuffix(strings.TrimSuffix(text, ">"), "/")
+		doc.editClosed(e)
+		return
+	}
+	doc.edits = append(doc.edits, &edit{start: e.Start, end: e.InnerStart, text: text})
+}
+
+// ReplaceInner replaces the content of the element
+func (doc *Document) ReplaceInner(e *Element, text string) {
+	if e.closed {
+		e.inner = &text
+		doc.editClosed(e)
+		return
+	}
+	doc.edits = append(doc.edits, &edit{start: e.InnerStart, end: e.InnerEnd, text: text})
+}
+
+// editClosed rewrites a self-closing element with its pending start tag and
+// content in a single edit
+func (doc *Document) editClosed(e *Element) {
+	startTag := e.startTag
+	if startTag == "" {
+		startTag = strings.TrimSuffix(string(doc.raw[e.Start:e.InnerStart]), "/>")
+	}
+	text := startTag + "/>"
+	if e.inner != nil {
+		text = startTag + ">" + *e.inner + "</" + e.Tag + ">"
+	}
+	if e.edit == nil {
+		e.edit = &edit{start: e.Start, end: e.End}
+		doc.edits = append(doc.edits, e.edit)
+	}
+	e.edit.text = text
+}
+
+// ReplaceItem replaces the content of an item
+func (doc *Document) ReplaceItem(item *Item, text string) {
+	doc.edits = append(doc.edits, &edit{start: item.InnerStart, end: item.InnerEnd, text: text})
+}
+
+// InsertItemBefore inserts text on its own line before an item
+func (doc *Document) InsertItemBefore(item *Item, text string) {
+	indent := leadingIndent(doc.raw, item.Start)
+	lineStart := lineStartAt(doc.raw, item.Start)
+	if lineStart+len(indent) == item.Start {
+		doc.edits = append(doc.edits, &edit{start: lineStart, end: lineStart, text: indent + text + doc.newline})
+	} else {
+		doc.edits = append(doc.edits, &edit{start: item.Start, end: item.Start, text: text})
+	}
+}
+
+// AppendItem inserts text on its own line after the last item of the element
+func (doc *Document) AppendItem(e *Element, text string) {
+	if len(e.Items) == 0 {
+		doc.ReplaceInner(e, doc.newline+doc.indent+doc.indent+text+doc.newline+doc.indent)
+		return
+	}
+	last := e.Items[len(e.Items)-1]
+	indent := leadingIndent(doc.raw, last.Start)
+	if lineStartAt(doc.raw, last.Start)+len(indent) != last.Start {
+		doc.edits = append(doc.edits, &edit{start: last.End, end: last.End, text: text})
+		return
+	}
+	doc.edits = append(doc.edits, &edit{start: last.End, end: last.End, text: doc.newline + indent + text})
+}
+
+// Remove removes the element, along with its line if nothing else is on it
+func (doc *Document) Remove(e *Element) {
+	doc.removeLine(e.Start, e.End)
+}
+
+// RemoveItem removes an item, along with its line if nothing else is on it
+func (doc *Document) RemoveItem(item *Item) {
+	doc.removeLine(item.Start, item.End)
+}
+
+// removeLine removes raw[start:end], along with its line if nothing else is
+// on it
+func (doc *Document) removeLine(start, end int) {
+	lineStart := start
+	for lineStart > 0 && (doc.raw[lineStart-1] == ' ' || doc.raw[lineStart-1] == '\t') {
+		lineStart--
+	}
+	lineEnd := end
+	for lineEnd < len(doc.raw) && (doc.raw[lineEnd] == ' ' || doc.raw[lineEnd] == '\t') {
+		lineEnd++
+	}
+	atLineStart := lineStart == 0 || doc.raw[lineStart-1] == '\n'
+	atLineEnd := lineEnd == len(doc.raw) || doc.raw[lineEnd] == '\n' || doc.raw[lineEnd] == '\r'
+	if atLineStart && atLineEnd {
+		start = lineStart
+		end = lineEnd
+		if end < len(doc.raw) && doc.raw[end] == '\r' {
+			end++
+		}
+		if end < len(doc.raw) && doc.raw[end] == '\n' {
+			end++
+		}
+	}
+	doc.edits = append(doc.edits, &edit{start: start, end: end})
+}
+
+// InsertBefore inserts text on its own line before the element
+func (doc *Document) InsertBefore(e *Element, text string) {
+	lineStart := e.Start
+	for lineStart > 0 && (doc.raw[lineStart-1] == ' ' || doc.raw[lineStart-1] == '\t') {
+		lineStart--
+	}
+	if lineStart == 0 || doc.raw[lineStart-1] == '\n' {
+		doc.edits = append(doc.edits, &edit{start: lineStart, end: lineStart, text: doc.indent + text + doc.newline})
+	} else {
+		doc.edits = append(doc.edits, &edit{start: e.Start, end: e.Start, text: text + doc.newline + doc.indent})
+	}
+}
+
+// InsertAfter inserts text on its own line after the element
+func (doc *Document) InsertAfter(e *Element, text string) {
+	doc.insertLines(e.End, []string{text})
+}
+
+// Append inserts text on its own line before </resources>
+func (doc *Document) Append(text string) {
+	doc.AppendLines([]string{text}, false)
+}
+
+// AppendLines inserts each text on its own line before </resources>,
+// preceded by a blank line if separate is true
+func (doc *Document) AppendLines(texts []string, separate bool) {
+	if len(texts) == 0 {
+		return
+	}
+
+	sb := &strings.Builder{}
+	if separate {
+		sb.WriteString(doc.newline)
+	}
+	for _, text := range texts {
+		sb.WriteString(doc.indent + text + doc.newline)
+	}
+
+	if doc.rootSelfClosing {
+		// <resources/> becomes <resources>...</resources>
+		doc.edits = append(doc.edits, &edit{
+			start: doc.rootTagEnd - 2,
+			end:   doc.rootTagEnd,
+			text:  ">" + doc.newline + sb.String() + "</" + tagResources + ">",
+		})
+		return
+	}
+
+	lineStart := doc.rootEnd
+	for lineStart > 0 && (doc.raw[lineStart-1] == ' ' || doc.raw[lineStart-1] == '\t') {
+		lineStart--
+	}
+	if lineStart > doc.rootTagEnd && doc.raw[lineStart-1] == '\n' {
+		doc.edits = append(doc.edits, &edit{start: lineStart, end: lineStart, text: sb.String()})
+	} else {
+		doc.edits = append(doc.edits, &edit{start: doc.rootEnd, end: doc.rootEnd, text: doc.newline + sb.String()})
+	}
+}
+
+// LastLineBlank reports whether the line before </resources> is blank
+func (doc *Document) LastLineBlank() bool {
+	if doc.rootSelfClosing {
+		return true
+	}
+	i := doc.rootEnd
+	for i > 0 && (doc.raw[i-1] == ' ' || doc.raw[i-1] == '\t') {
+		i--
+	}
+	if i <= doc.rootTagEnd || doc.raw[i-1] != '\n' {
+		return false
+	}
+	i--
+	if i > 0 && doc.raw[i-1] == '\r' {
+		i--
+	}
+	return len(strings.TrimSpace(string(doc.raw[lineStartAt(doc.raw, i):i]))) == 0
+}
+
+// SetRootAttr adds an attribute to <resources> if it is missing
+func (doc *Document) SetRootAttr(name, value string) {
+	if attrIndex(doc.rootAttrs, name) >= 0 {
+		return
+	}
+	pos := doc.rootTagEnd - 1
+	if doc.rootSelfClosing {
+		pos--
+	}
+	sb := &strings.Builder{}
+	writeAttr(sb, name, value)
+	doc.edits = append(doc.edits, &edit{start: pos, end: pos, text: sb.String()})
+	doc.rootAttrs = append(doc.rootAttrs, NewAttr(name, value))
+}
+
+// Changed reports whether there are pending edits
+func (doc *Document) Changed() bool {
+	return len(doc.edits) > 0
+}
+
+// Bytes returns the content with all edits applied, overlapping edits are
+// an error
+func (doc *Document) Bytes() ([]byte, error) {
+	if len(doc.edits) == 0 {
+		return doc.raw, nil
+	}
+
+	text, err := doc.rendered(0, len(doc.raw))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(text), nil
+}
+
+func lineAt(raw []byte, offset int) int {
+	if offset > len(raw) {
+		offset = len(raw)
+	}
+	return bytes.Count(raw[:offset], []byte("\n")) + 1
+}
+
+func lineStartAt(raw []byte, offset int) int {
+	i := bytes.LastIndexByte(raw[:offset], '\n')
+	return i + 1
+}
+
+func leadingIndent(raw []byte, offset int) string {
+	lineStart := lineStartAt(raw, offset)
+	indent := raw[lineStart:offset]
+	if len(bytes.TrimLeft(indent, " \t")) != 0 {
+		return "    "
+	}
+	return string(indent)
+}
@@ -0,0 +1,169 @@
+package resxml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		edit func(doc *Document)
+		want string
+	}{
+		{
+			name: "no edit keeps every byte",
+			raw:  "<?xml version=\"1.0\"?>\r\n<!-- head -->\r\n<resources>\r\n\t<string name=\"a\"><![CDATA[<b>]]></string>\r\n</resources>\r\ntrailing",
+			edit: func(doc *Document) {},
+			want: "<?xml version=\"1.0\"?>\r\n<!-- head -->\r\n<resources>\r\n\t<string name=\"a\"><![CDATA[<b>]]></string>\r\n</resources>\r\ntrailing",
+		},
+		{
+			name: "crlf append",
+			raw:  "<resources>\r\n    <string name=\"a\">A</string>\r\n</resources>\r\n",
+			edit: func(doc *Document) { doc.Append(`<string name="b">B</string>`) },
+			want: "<resources>\r\n    <string name=\"a\">A</string>\r\n    <string name=\"b\">B</string>\r\n</resources>\r\n",
+		},
+		{
+			name: "comments kept around a replaced value",
+			raw:  "<resources>\n    <!-- a -->\n    <string name=\"a\">A</string> <!-- after -->\n</resources>\n",
+			edit: func(doc *Document) { doc.ReplaceInner(doc.Lookup(TagString, "a"), "AA") },
+			want: "<resources>\n    <!-- a -->\n    <string name=\"a\">AA</string> <!-- after -->\n</resources>\n",
+		},
+		{
+			name: "cdata replaced",
+			raw:  "<resources>\n    <string name=\"a\"><![CDATA[<b>x</b>]]></string>\n    <string name=\"b\"><![CDATA[y]]></string>\n</resources>\n",
+			edit: func(doc *Document) { doc.ReplaceInner(doc.Lookup(TagString, "a"), "z") },
+			want: "<resources>\n    <string name=\"a\">z</string>\n    <string name=\"b\"><![CDATA[y]]></string>\n</resources>\n",
+		},
+		{
+			name: "self-closing value",
+			raw:  "<resources>\n    <string name=\"a\"/>\n</resources>\n",
+			edit: func(doc *Document) { doc.ReplaceInner(doc.Lookup(TagString, "a"), "A") },
+			want: "<resources>\n    <string name=\"a\">A</string>\n</resources>\n",
+		},
+		{
+			name: "self-closing start tag",
+			raw:  "<resources>\n    <string name=\"a\" />\n</resources>\n",
+			edit: func(doc *Document) {
+				doc.ReplaceStartTag(doc.Lookup(TagString, "a"), `<string name="a" translatable="false">`)
+			},
+			want: "<resources>\n    <string name=\"a\" translatable=\"false\"/>\n</resources>\n",
+		},
+		{
+			name: "self-closing start tag and value",
+			raw:  "<resources>\n    <string name=\"a\"/>\n</resources>\n",
+			edit: func(doc *Document) {
+				e := doc.Lookup(TagString, "a")
+				doc.ReplaceStartTag(e, `<string name="a" formatted="false">`)
+				doc.ReplaceInner(e, "100% sure")
+			},
+			want: "<resources>\n    <string name=\"a\" formatted=\"false\">100% sure</string>\n</resources>\n",
+		},
+		{
+			name: "self-closing value and start tag",
+			raw:  "<resources>\n    <string name=\"a\"/>\n</resources>\n",
+			edit: func(doc *Document) {
+				e := doc.Lookup(TagString, "a")
+				doc.ReplaceInner(e, "100% sure")
+				doc.ReplaceStartTag(e, `<string name="a" formatted="false">`)
+			},
+			want: "<resources>\n    <string name=\"a\" formatted=\"false\">100% sure</string>\n</resources>\n",
+		},
+		{
+			name: "start tag and value",
+			raw:  "<resources>\n    <string name=\"a\">A</string>\n</resources>\n",
+			edit: func(doc *Document) {
+				e := doc.Lookup(TagString, "a")
+				doc.ReplaceStartTag(e, `<string name="a" formatted="false">`)
+				doc.ReplaceInner(e, "100% sure")
+			},
+			want: "<resources>\n    <string name=\"a\" formatted=\"false\">100% sure</string>\n</resources>\n",
+		},
+		{
+			name: "two elements on one line",
+			raw:  "<resources>\n    <string name=\"a\">A</string><string name=\"b\">B</string>\n</resources>\n",
+			edit: func(doc *Document) {
+				doc.ReplaceInner(doc.Lookup(TagString, "b"), "BB")
+				doc.Remove(doc.Lookup(TagString, "a"))
+			},
+			want: "<resources>\n    <string name=\"b\">BB</string>\n</resources>\n",
+		},
+		{
+			name: "content after resources",
+			raw:  "<resources>\n    <string name=\"a\">A</string>\n</resources>\n<!-- end -->\n",
+			edit: func(doc *Document) { doc.Append(`<string name="b">B</string>`) },
+			want: "<resources>\n    <string name=\"a\">A</string>\n    <string name=\"b\">B</string>\n</resources>\n<!-- end -->\n",
+		},
+		{
+			name: "remove own line",
+			raw:  "<resources>\n    <string name=\"a\">A</string>\n    <string name=\"b\">B</string>\n</resources>\n",
+			edit: func(doc *Document) { doc.Remove(doc.Lookup(TagString, "a")) },
+			want: "<resources>\n    <string name=\"b\">B</string>\n</resources>\n",
+		},
+		{
+			name: "self-closing root",
+			raw:  "<?xml version=\"1.0\"?>\n<resources/>\n",
+			edit: func(doc *Document) { doc.Append(`<string name="a">A</string>`) },
+			want: "<?xml version=\"1.0\"?>\n<resources>\n    <string name=\"a\">A</string>\n</resources>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("Parse() err:%v", err)
+			}
+			tt.edit(doc)
+			got, err := doc.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes() err:%v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Bytes() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlappingEdits(t *testing.T) {
+	doc, err := Parse([]byte("<resources>\n    <string name=\"a\">A</string>\n</resources>\n"))
+	if err != nil {
+		t.Fatalf("Parse() err:%v", err)
+	}
+	e := doc.Lookup(TagString, "a")
+	doc.Replace(e, `<string name="b">B</string>`)
+	doc.ReplaceInner(e, "AA")
+	if _, err = doc.Bytes(); err == nil || !strings.Contains(err.Error(), "overlapping") {
+		t.Errorf("Bytes() err:%v, want overlapping edits", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	raw := "<resources>\r\n" +
+		"    <!-- greeting -->\r\n" +
+		"    <string name=\"a\"><![CDATA[x]]></string>\r\n" +
+		"    <string name=\"b\"/><plurals name=\"c\"><item quantity=\"one\">1</item></plurals>\r\n" +
+		"</resources>\r\n"
+	doc, err := Parse([]byte(raw))
+	if err != nil {
+		t.Fatalf("Parse() err:%v", err)
+	}
+	if doc.Newline() != "\r\n" {
+		t.Errorf("Newline() = %q, want crlf", doc.Newline())
+	}
+	if len(doc.Comments()) != 1 || doc.Comments()[0].Text != "greeting" {
+		t.Errorf("Comments() = %v", doc.Comments())
+	}
+	if v := doc.Lookup(TagString, "a").Value(); v != "<![CDATA[x]]>" {
+		t.Errorf("Value() = %q, want cdata kept", v)
+	}
+	b := doc.Lookup(TagString, "b")
+	if !b.SelfClosing() || b.Line != 4 {
+		t.Errorf("b self-closing:%v line:%d", b.SelfClosing(), b.Line)
+	}
+	c := doc.Lookup(TagPlurals, "c")
+	if c.Line != 4 || len(c.Items) != 1 || c.Items[0].Attr("quantity") != "one" {
+		t.Errorf("c line:%d items:%v", c.Line, c.Items)
+	}
+}
@@ -0,0 +1,135 @@
+package resxml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// ToolsNamespace is the namespace of tools:* attributes
+const ToolsNamespace = "http://schemas.android.com/tools"
+
+// AttrName returns the qualified name of an attribute, e.g. tools:ignore
+func AttrName(attr xml.Attr) string {
+	return qualifiedName(attr.Name)
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// NewAttr returns an attribute from a qualified name
+func NewAttr(name, value string) xml.Attr {
+	attr := xml.Attr{Value: value}
+	if i := strings.IndexRune(name, ':'); i >= 0 {
+		attr.Name.Space = name[:i]
+		attr.Name.Local = name[i+1:]
+	} else {
+		attr.Name.Local = name
+	}
+	return attr
+}
+
+func attrIndex(attrs []xml.Attr, name string) int {
+	for i, attr := range attrs {
+		if qualifiedName(attr.Name) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func attrValue(attrs []xml.Attr, name string) string {
+	if i := attrIndex(attrs, name); i >= 0 {
+		return attrs[i].Value
+	}
+	return ""
+}
+
+func writeAttr(sb *strings.Builder, name, value string) {
+	sb.WriteRune(' ')
+	sb.WriteString(name)
+	sb.WriteString(`="`)
+	_ = xml.EscapeText(sb, []byte(value))
+	sb.WriteRune('"')
+}
+
+func writeStartElement(sb *strings.Builder, tag, name string, attrs []xml.Attr) {
+	sb.WriteRune('<')
+	sb.WriteString(tag)
+	if name != "" {
+		writeAttr(sb, "name", name)
+	}
+	for _, attr := range attrs {
+		writeAttr(sb, qualifiedName(attr.Name), attr.Value)
+	}
+	sb.WriteRune('>')
+}
+
+// Formatter renders resource elements with the layout of a document
+type Formatter struct {
+	Indent  string
+	Newline string
+}
+
+// Formatter returns a formatter matching the document layout
+func (doc *Document) Formatter() *Formatter {
+	return &Formatter{
+		Indent:  doc.indent,
+		Newline: doc.newline,
+	}
+}
+
+// StartTag renders the start tag of an element
+func (f *Formatter) StartTag(tag, name string, attrs []xml.Attr) string {
+	sb := &strings.Builder{}
+	writeStartElement(sb, tag, name, attrs)
+	return sb.String()
+}
+
+// Element renders a single line element, value is raw inner xml
+func (f *Formatter) Element(tag, name string, attrs []xml.Attr, value string) string {
+	sb := &strings.Builder{}
+	writeStartElement(sb, tag, name, attrs)
+	sb.WriteString(value)
+	sb.WriteString("</" + tag + ">")
+	return sb.String()
+}
+
+// String renders a <string> element
+func (f *Formatter) String(name string, attrs []xml.Attr, value string) string {
+	return f.Element(TagString, name, attrs, value)
+}
+
+// Plurals renders a <plurals> element, quantities in the given order
+func (f *Formatter) Plurals(name string, attrs []xml.Attr, quantities []string, items map[string]string) string {
+	sb := &strings.Builder{}
+	writeStartElement(sb, TagPlurals, name, attrs)
+	for _, q := range quantities {
+		sb.WriteString(f.Newline + f.Indent + f.Indent)
+		writeStartElement(sb, TagItem, "", []xml.Attr{NewAttr("quantity", q)})
+		sb.WriteString(items[q])
+		sb.WriteString("</" + TagItem + ">")
+	}
+	sb.WriteString(f.Newline + f.Indent + "</" + TagPlurals + ">")
+	return sb.String()
+}
+
+// Item renders an <item> element
+func (f *Formatter) Item(attrs []xml.Attr, value string) string {
+	return f.Element(TagItem, "", attrs, value)
+}
+
+// Array renders an array element like <string-array>
+func (f *Formatter) Array(tag, name string, attrs []xml.Attr, items []string) string {
+	sb := &strings.Builder{}
+	writeStartElement(sb, tag, name, attrs)
+	for _, v := range items {
+		sb.WriteString(f.Newline + f.Indent + f.Indent)
+		sb.WriteString("<" + TagItem + ">" + v + "</" + TagItem + ">")
+	}
+	sb.WriteString(f.Newline + f.Indent + "</" + tag + ">")
+	return sb.String()
+}
@@ -0,0 +1,214 @@
+package resxml
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entry is a new resource, Text is written on its own line
+type Entry struct {
+	Name string
+	Text string
+}
+
+// InsertLinesAfter inserts each text on its own line after the element
+func (doc *Document) InsertLinesAfter(e *Element, texts []string) {
+	doc.insertLines(e.End, texts)
+}
+
+// InsertLinesAfterComment inserts each text on its own line after the comment
+func (doc *Document) InsertLinesAfterComment(c *Comment, texts []string) {
+	doc.insertLines(c.End, texts)
+}
+
+// InsertLinesBefore inserts each text on its own line before the element
+func (doc *Document) InsertLinesBefore(e *Element, texts []string) {
+	if len(texts) == 0 {
+		return
+	}
+	doc.InsertBefore(e, strings.Join(texts, doc.newline+doc.indent))
+}
+
+func (doc *Document) insertLines(pos int, texts []string) {
+	if len(texts) == 0 {
+		return
+	}
+	sb := &strings.Builder{}
+	for _, text := range texts {
+		sb.WriteString(doc.newline + doc.indent + text)
+	}
+	doc.edits = append(doc.edits, &edit{start: pos, end: pos, text: sb.String()})
+}
+
+// unit is a named element along with the comments right above it
+type unit struct {
+	name  string
+	start int
+	end   int
+}
+
+// Resort sorts the named elements by name along with added entries, each
+// element keeps its text and the comments right above it. Other content
+// found between the elements is kept after them. Edits made so far are
+// applied first
+func (doc *Document) Resort(added []*Entry) error {
+	block, err := doc.ManagedBlock()
+	if err != nil {
+		return err
+	}
+	if block != nil {
+		return fmt.Errorf("cannot sort a document with a managed block")
+	}
+
+	comments := doc.comments
+	var units []*unit
+	for _, e := range doc.elements {
+		if e.Name == "" {
+			continue
+		}
+		start, end, ok := doc.ownLines(e.Start, e.End)
+		if !ok {
+			return fmt.Errorf("cannot sort, %v '%v' at line %d shares its line", e.Tag, e.Name, e.Line)
+		}
+		// comments right above the element belong to it
+		for i := len(comments) - 1; i >= 0; i-- {
+			c := comments[i]
+			if c.End > start {
+				continue
+			}
+			cStart, cEnd, ok := doc.ownLines(c.Start, c.End)
+			if !ok || cEnd != start {
+				break
+			}
+			start = cStart
+		}
+		units = append(units, &unit{name: e.Name, start: start, end: end})
+	}
+
+	if len(units) == 0 {
+		texts := make([]string, 0, len(added))
+		for _, v := range added {
+			texts = append(texts, v.Text)
+		}
+		doc.AppendLines(texts, false)
+		return nil
+	}
+
+	spanStart, spanEnd := units[0].start, units[len(units)-1].end
+
+	// leftovers between the units
+	var others []string
+	pos := spanStart
+	for _, u := range units {
+		text, err := doc.rendered(pos, u.start)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) != "" {
+			others = append(others, text)
+		}
+		pos = u.end
+	}
+
+	type sortable struct {
+		name string
+		text string
+	}
+	var all []*sortable
+	for _, u := range units {
+		text, err := doc.rendered(u.start, u.end)
+		if err != nil {
+			return err
+		}
+		all = append(all, &sortable{name: u.name, text: text})
+	}
+	for _, v := range added {
+		all = append(all, &sortable{name: v.Name, text: doc.indent + v.Text + doc.newline})
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].name < all[j].name
+	})
+
+	sb := &strings.Builder{}
+	for _, v := range all {
+		sb.WriteString(v.text)
+	}
+	for _, v := range others {
+		sb.WriteString(v)
+	}
+
+	// the sorted span replaces the edits made inside of it
+	kept := doc.edits[:0]
+	for _, e := range doc.edits {
+		if !doc.inside(e, spanStart, spanEnd) {
+			kept = append(kept, e)
+		}
+	}
+	doc.edits = kept
+	if sb.String() != string(doc.raw[spanStart:spanEnd]) {
+		doc.edits = append(doc.edits, &edit{start: spanStart, end: spanEnd, text: sb.String()})
+	}
+	return nil
+}
+
+// ownLines returns the span of whole lines holding start to end, ok is false
+// if anything else is on these lines
+func (doc *Document) ownLines(start, end int) (lineStart, lineEnd int, ok bool) {
+	lineStart = lineStartAt(doc.raw, start)
+	if len(bytes.TrimSpace(doc.raw[lineStart:start])) != 0 {
+		return
+	}
+	lineEnd = end
+	for lineEnd < len(doc.raw) && doc.raw[lineEnd] != '\n' {
+		lineEnd++
+	}
+	if len(bytes.TrimSpace(doc.raw[end:lineEnd])) != 0 {
+		return
+	}
+	if lineEnd < len(doc.raw) {
+		lineEnd++
+	}
+	return lineStart, lineEnd, true
+}
+
+// inside reports whether an edit is made inside of start to end. Ranges are
+// half-open, an insertion at end belongs to the range starting there, unless
+// end is the end of the document, so adjacent ranges never share an edit
+func (doc *Document) inside(e *edit, start, end int) bool {
+	if e.start < start || e.end > end {
+		return false
+	}
+	return e.start != end || e.end != end || end == len(doc.raw)
+}
+
+// rendered returns the content between start and end with the edits made
+// inside of it applied, edits overlapping each other are an error
+func (doc *Document) rendered(start, end int) (string, error) {
+	var edits []*edit
+	for _, e := range doc.edits {
+		if doc.inside(e, start, end) {
+			edits = append(edits, e)
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end < edits[j].end
+	})
+
+	sb := &strings.Builder{}
+	pos := start
+	for _, e := range edits {
+		if e.start < pos {
+			return "", fmt.Errorf("overlapping edits at line %d", lineAt(doc.raw, e.start))
+		}
+		sb.Write(doc.raw[pos:e.start])
+		sb.WriteString(e.text)
+		pos = e.end
+	}
+	sb.Write(doc.raw[pos:end])
+	return sb.String(), nil
+}
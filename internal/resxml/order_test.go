@@ -0,0 +1,75 @@
+package resxml
+
+import (
+	"testing"
+)
+
+func TestResort(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		edit  func(doc *Document)
+		added []*Entry
+		want  string
+	}{
+		{
+			name:  "sorted with added entries and comments",
+			raw:   "<resources>\n    <!-- c -->\n    <string name=\"c\">C</string>\n    <string name=\"a\">A</string>\n</resources>\n",
+			edit:  func(doc *Document) {},
+			added: []*Entry{{Name: "b", Text: `<string name="b">B</string>`}},
+			want:  "<resources>\n    <string name=\"a\">A</string>\n    <string name=\"b\">B</string>\n    <!-- c -->\n    <string name=\"c\">C</string>\n</resources>\n",
+		},
+		{
+			name: "insertion at a unit boundary moves with the unit",
+			raw:  "<resources>\n    <string name=\"c\">C</string>\n    <string name=\"a\">A</string>\n</resources>\n",
+			edit: func(doc *Document) {
+				doc.InsertBefore(doc.Lookup(TagString, "a"), "<!-- inserted -->")
+			},
+			want: "<resources>\n    <!-- inserted -->\n    <string name=\"a\">A</string>\n    <string name=\"c\">C</string>\n</resources>\n",
+		},
+		{
+			name: "insertion at the first unit",
+			raw:  "<resources>\n    <string name=\"c\">C</string>\n    <string name=\"a\">A</string>\n</resources>\n",
+			edit: func(doc *Document) {
+				doc.InsertBefore(doc.Lookup(TagString, "c"), "<!-- inserted -->")
+			},
+			want: "<resources>\n    <string name=\"a\">A</string>\n    <!-- inserted -->\n    <string name=\"c\">C</string>\n</resources>\n",
+		},
+		{
+			name: "insertion at the end of the span is kept",
+			raw:  "<resources>\n    <string name=\"c\">C</string>\n    <string name=\"a\">A</string>\n</resources>\n",
+			edit: func(doc *Document) {
+				doc.Append("<!-- appended -->")
+			},
+			want: "<resources>\n    <string name=\"a\">A</string>\n    <string name=\"c\">C</string>\n    <!-- appended -->\n</resources>\n",
+		},
+		{
+			name: "edited value",
+			raw:  "<resources>\n    <string name=\"c\">C</string>\n    <string name=\"a\">A</string>\n</resources>\n",
+			edit: func(doc *Document) {
+				doc.ReplaceInner(doc.Lookup(TagString, "c"), "CC")
+			},
+			want: "<resources>\n    <string name=\"a\">A</string>\n    <string name=\"c\">CC</string>\n</resources>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("Parse() err:%v", err)
+			}
+			tt.edit(doc)
+			err = doc.Resort(tt.added)
+			if err != nil {
+				t.Fatalf("Resort() err:%v", err)
+			}
+			got, err := doc.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes() err:%v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Bytes() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
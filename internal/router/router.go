@@ -0,0 +1,175 @@
+// Package router distributes keys to the res directories of a multi-module
+// android project by rules
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/master-g/i18n/internal/model"
+)
+
+// Rule routes the resources matching a key prefix, a regular expression or
+// a value of the module column to a res directory
+type Rule struct {
+	Prefix string `json:"prefix,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+	Module string `json:"module,omitempty"`
+	Res    string `json:"res"`
+	re     *regexp.Regexp
+}
+
+func (r *Rule) String() string {
+	switch {
+	case r.Prefix != "":
+		return fmt.Sprintf("prefix %v", r.Prefix)
+	case r.Regex != "":
+		return fmt.Sprintf("regex %v", r.Regex)
+	}
+	return fmt.Sprintf("%v %v", model.ModuleColumn, r.Module)
+}
+
+// Match reports whether a resource matches the rule, module is the value of
+// its module column
+func (r *Rule) Match(name, module string) bool {
+	switch {
+	case r.Prefix != "":
+		return strings.HasPrefix(name, r.Prefix)
+	case r.re != nil:
+		return r.re.MatchString(name)
+	}
+	return module != "" && strings.EqualFold(module, r.Module)
+}
+
+// Config is a set of routing rules, resources matching no rule go to Default
+// if it is set
+type Config struct {
+	Routes  []*Rule `json:"routes"`
+	Default string  `json:"default,omitempty"`
+}
+
+// Load reads and validates a routing config file
+func Load(path string) (c *Config, err error) {
+	var raw []byte
+	raw, err = ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read routing config %v, err:%v", path, err)
+	}
+	c = &Config{}
+	err = json.Unmarshal(raw, c)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse routing config %v, err:%v", path, err)
+	}
+	if len(c.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found in %v", path)
+	}
+	for i, r := range c.Routes {
+		matchers := 0
+		for _, v := range []string{r.Prefix, r.Regex, r.Module} {
+			if v != "" {
+				matchers++
+			}
+		}
+		if matchers != 1 {
+			return nil, fmt.Errorf("route %d in %v needs exactly one of prefix, regex or %v", i+1, path, model.ModuleColumn)
+		}
+		if r.Res == "" {
+			return nil, fmt.Errorf("route %d in %v has no res", i+1, path)
+		}
+		if r.Regex != "" {
+			r.re, err = regexp.Compile(r.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("route %d in %v has an invalid regex, err:%v", i+1, path, err)
+			}
+		}
+	}
+	return
+}
+
+// Result of routing
+type Result struct {
+	// Routed holds the data of each res directory, by language
+	Routed map[string]map[string]map[string]string
+	// Unmatched holds the resources matching no rule, without a default
+	Unmatched []string
+	// Ambiguous holds the resources matching rules of different res
+	// directories, along with the rules
+	Ambiguous map[string][]*Rule
+}
+
+// Resources returns the res directories data is routed to, sorted
+func (r *Result) Resources() []string {
+	res := make([]string, 0, len(r.Routed))
+	for v := range r.Routed {
+		res = append(res, v)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Route splits data by language into res directories, modules holds the
+// module column by resource name. Unmatched and ambiguous resources are
+// left out
+func (c *Config) Route(data map[string]map[string]string, modules map[string]string) *Result {
+	result := &Result{
+		Routed:    make(map[string]map[string]map[string]string),
+		Ambiguous: make(map[string][]*Rule),
+	}
+
+	// resource name -> res directory
+	targets := make(map[string]string)
+	unmatched := make(map[string]bool)
+	for _, kvs := range data {
+		for key := range kvs {
+			name, _ := model.SplitResourceKey(key)
+			if _, ok := targets[name]; ok || unmatched[name] || result.Ambiguous[name] != nil {
+				continue
+			}
+			var matched []*Rule
+			res := make(map[string]bool)
+			for _, r := range c.Routes {
+				if r.Match(name, modules[name]) {
+					matched = append(matched, r)
+					res[r.Res] = true
+				}
+			}
+			switch {
+			case len(res) > 1:
+				result.Ambiguous[name] = matched
+			case len(res) == 1:
+				targets[name] = matched[0].Res
+			case c.Default != "":
+				targets[name] = c.Default
+			default:
+				unmatched[name] = true
+			}
+		}
+	}
+	for name := range unmatched {
+		result.Unmatched = append(result.Unmatched, name)
+	}
+	sort.Strings(result.Unmatched)
+
+	for lang, kvs := range data {
+		for key, value := range kvs {
+			name, _ := model.SplitResourceKey(key)
+			res, ok := targets[name]
+			if !ok {
+				continue
+			}
+			if result.Routed[res] == nil {
+				result.Routed[res] = make(map[string]map[string]string)
+			}
+			if result.Routed[res][lang] == nil {
+				result.Routed[res][lang] = make(map[string]string)
+			}
+			result.Routed[res][lang][key] = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,166 @@
+package router
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func loadConfig(t *testing.T, raw string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "routes.json")
+	err := ioutil.WriteFile(path, []byte(raw), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{name: "valid", raw: `{"routes": [{"prefix": "a_", "res": "a/res"}, {"regex": "^b", "res": "b/res"}, {"module": "c", "res": "c/res"}]}`},
+		{name: "not json", raw: `routes`, wantErr: "cannot parse"},
+		{name: "no routes", raw: `{"default": "app/res"}`, wantErr: "no routes"},
+		{name: "no matcher", raw: `{"routes": [{"res": "a/res"}]}`, wantErr: "exactly one"},
+		{name: "two matchers", raw: `{"routes": [{"prefix": "a", "regex": "a", "res": "a/res"}]}`, wantErr: "exactly one"},
+		{name: "no res", raw: `{"routes": [{"prefix": "a"}]}`, wantErr: "has no res"},
+		{name: "invalid regex", raw: `{"routes": [{"regex": "(", "res": "a/res"}]}`, wantErr: "invalid regex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(t, tt.raw)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Load() err:%v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() err:%v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	data := map[string]map[string]string{
+		"en": {
+			"feed_title":        "Feed",
+			"feed_count[one]":   "one post",
+			"feed_count[other]": "%d posts",
+			"settings_title":    "Settings",
+			"about":             "About",
+			"pay_button":        "Pay",
+			"orphan":            "Orphan",
+		},
+		"fr": {
+			"feed_title":     "Fil",
+			"settings_title": "Réglages",
+		},
+	}
+	modules := map[string]string{
+		"about":      "Settings",
+		"pay_button": "checkout",
+	}
+
+	tests := []struct {
+		name      string
+		raw       string
+		routed    map[string][]string
+		unmatched []string
+		ambiguous map[string][]string
+	}{
+		{
+			name: "prefix, regex and module",
+			raw: `{"routes": [
+				{"prefix": "feed_", "res": "feed/res"},
+				{"regex": "^settings_", "res": "settings/res"},
+				{"module": "settings", "res": "settings/res"}
+			]}`,
+			routed: map[string][]string{
+				"feed/res":     {"en:feed_count[one]", "en:feed_count[other]", "en:feed_title", "fr:feed_title"},
+				"settings/res": {"en:about", "en:settings_title", "fr:settings_title"},
+			},
+			unmatched: []string{"orphan", "pay_button"},
+		},
+		{
+			name: "default",
+			raw:  `{"routes": [{"prefix": "feed_", "res": "feed/res"}], "default": "app/res"}`,
+			routed: map[string][]string{
+				"feed/res": {"en:feed_count[one]", "en:feed_count[other]", "en:feed_title", "fr:feed_title"},
+				"app/res":  {"en:about", "en:orphan", "en:pay_button", "en:settings_title", "fr:settings_title"},
+			},
+		},
+		{
+			name: "rules of the same res are not ambiguous",
+			raw: `{"routes": [
+				{"prefix": "feed_", "res": "feed/res"},
+				{"regex": "_title$", "res": "feed/res"}
+			]}`,
+			routed: map[string][]string{
+				"feed/res": {"en:feed_count[one]", "en:feed_count[other]", "en:feed_title", "en:settings_title", "fr:feed_title", "fr:settings_title"},
+			},
+			unmatched: []string{"about", "orphan", "pay_button"},
+		},
+		{
+			name: "ambiguous",
+			raw: `{"routes": [
+				{"prefix": "feed_", "res": "feed/res"},
+				{"regex": "_title$", "res": "app/res"},
+				{"module": "checkout", "res": "checkout/res"}
+			], "default": "app/res"}`,
+			routed: map[string][]string{
+				"feed/res":     {"en:feed_count[one]", "en:feed_count[other]"},
+				"app/res":      {"en:about", "en:orphan", "en:settings_title", "fr:settings_title"},
+				"checkout/res": {"en:pay_button"},
+			},
+			ambiguous: map[string][]string{
+				"feed_title": {"prefix feed_", "regex _title$"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := loadConfig(t, tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := c.Route(data, modules)
+
+			routed := make(map[string][]string)
+			for _, res := range result.Resources() {
+				for lang, kvs := range result.Routed[res] {
+					for key := range kvs {
+						routed[res] = append(routed[res], lang+":"+key)
+					}
+				}
+				sort.Strings(routed[res])
+			}
+			if !reflect.DeepEqual(routed, tt.routed) {
+				t.Errorf("Routed = %v, want %v", routed, tt.routed)
+			}
+			if !reflect.DeepEqual(result.Unmatched, tt.unmatched) {
+				t.Errorf("Unmatched = %v, want %v", result.Unmatched, tt.unmatched)
+			}
+			ambiguous := make(map[string][]string)
+			for name, rules := range result.Ambiguous {
+				for _, r := range rules {
+					ambiguous[name] = append(ambiguous[name], r.String())
+				}
+			}
+			if tt.ambiguous == nil {
+				tt.ambiguous = map[string][]string{}
+			}
+			if !reflect.DeepEqual(ambiguous, tt.ambiguous) {
+				t.Errorf("Ambiguous = %v, want %v", ambiguous, tt.ambiguous)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package bundle_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/master-g/i18n/pkg/bundle"
+)
+
+var compressions = []bundle.Compression{bundle.CompressionNone, bundle.CompressionDeflate, bundle.CompressionZstd}
+
+// testData returns languages lang00... with keys string_key_0...
+func testData(languages, keys int) map[string]map[string]string {
+	data := make(map[string]map[string]string)
+	for i := 0; i < languages; i++ {
+		kvs := make(map[string]string)
+		for j := 0; j < keys; j++ {
+			kvs[fmt.Sprintf("string_key_%d", j)] = fmt.Sprintf("translation %d of string %d, with some more text", i, j)
+		}
+		data[fmt.Sprintf("lang%02d", i)] = kvs
+	}
+	return data
+}
+
+// writeBundle encodes data following the layout documented in format.go,
+// independently of the writer of the i18n command
+func writeBundle(tb testing.TB, meta *bundle.Metadata, data map[string]map[string]string, c bundle.Compression) []byte {
+	le := binary.LittleEndian
+	var metaRaw []byte
+	if meta != nil {
+		var err error
+		metaRaw, err = json.Marshal(meta)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	languages := make([]string, 0, len(data))
+	for lang := range data {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	dirSize := 0
+	sections := make([][]byte, 0, len(languages))
+	rawSizes := make([]int, 0, len(languages))
+	for _, lang := range languages {
+		raw := stringTable(data[lang])
+		sections = append(sections, compress(tb, c, raw))
+		rawSizes = append(rawSizes, len(raw))
+		dirSize += 2 + len(lang) + 12
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(bundle.Magic)
+	_ = binary.Write(buf, le, bundle.Version)
+	buf.WriteByte(byte(c))
+	buf.WriteByte(0)
+	_ = binary.Write(buf, le, uint32(len(metaRaw)))
+	_ = binary.Write(buf, le, uint32(len(languages)))
+	buf.Write(metaRaw)
+	offset := buf.Len() + dirSize
+	for i, lang := range languages {
+		_ = binary.Write(buf, le, uint16(len(lang)))
+		buf.WriteString(lang)
+		_ = binary.Write(buf, le, uint32(offset))
+		_ = binary.Write(buf, le, uint32(len(sections[i])))
+		_ = binary.Write(buf, le, uint32(rawSizes[i]))
+		offset += len(sections[i])
+	}
+	for _, section := range sections {
+		buf.Write(section)
+	}
+	return buf.Bytes()
+}
+
+// stringTable encodes kvs as a string table with entries sorted by key hash
+func stringTable(kvs map[string]string) []byte {
+	le := binary.LittleEndian
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		hi, hj := bundle.Hash(keys[i]), bundle.Hash(keys[j])
+		if hi != hj {
+			return hi < hj
+		}
+		return keys[i] < keys[j]
+	})
+
+	index := &bytes.Buffer{}
+	blob := &bytes.Buffer{}
+	_ = binary.Write(index, le, uint32(len(keys)))
+	for _, key := range keys {
+		_ = binary.Write(index, le, bundle.Hash(key))
+		_ = binary.Write(index, le, uint32(blob.Len()))
+		_ = binary.Write(index, le, uint32(len(key)))
+		blob.WriteString(key)
+		_ = binary.Write(index, le, uint32(blob.Len()))
+		_ = binary.Write(index, le, uint32(len(kvs[key])))
+		blob.WriteString(kvs[key])
+	}
+	index.Write(blob.Bytes())
+	return index.Bytes()
+}
+
+func compress(tb testing.TB, c bundle.Compression, raw []byte) []byte {
+	switch c {
+	case bundle.CompressionDeflate:
+		buf := &bytes.Buffer{}
+		fw, err := flate.NewWriter(buf, flate.BestCompression)
+		if err == nil {
+			_, err = fw.Write(raw)
+		}
+		if err == nil {
+			err = fw.Close()
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return buf.Bytes()
+	case bundle.CompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		defer enc.Close()
+		return enc.EncodeAll(raw, nil)
+	}
+	return raw
+}
+
+func TestRoundTrip(t *testing.T) {
+	meta := &bundle.Metadata{Version: "test", SourceFiles: []string{"a.csv"}}
+	data := testData(3, 100)
+	data["lang01"]["empty"] = ""
+	data["lang02"]["unicode"] = "héllo, 世界  "
+
+	for _, c := range compressions {
+		t.Run(c.String(), func(t *testing.T) {
+			b, err := bundle.Open(writeBundle(t, meta, data, c))
+			if err != nil {
+				t.Fatalf("Open() err:%v", err)
+			}
+			if b.Compression() != c {
+				t.Errorf("Compression() = %v, want %v", b.Compression(), c)
+			}
+			if m := b.Metadata(); m == nil || m.Version != meta.Version || len(m.SourceFiles) != 1 {
+				t.Errorf("Metadata() = %+v", m)
+			}
+			if got := b.Languages(); len(got) != len(data) {
+				t.Errorf("Languages() = %v", got)
+			}
+			for lang, kvs := range data {
+				table, err := b.Table(lang)
+				if err != nil {
+					t.Fatalf("Table(%v) err:%v", lang, err)
+				}
+				if table.Len() != len(kvs) {
+					t.Errorf("%v Len() = %d, want %d", lang, table.Len(), len(kvs))
+				}
+				for key, want := range kvs {
+					got, ok := table.Lookup(key)
+					if !ok || got != want {
+						t.Errorf("%v Lookup(%v) = %q %v, want %q", lang, key, got, ok, want)
+					}
+				}
+				if _, ok := table.Lookup("missing"); ok {
+					t.Errorf("%v Lookup(missing) found", lang)
+				}
+			}
+			if _, err = b.Table("missing"); err == nil {
+				t.Errorf("Table(missing) found")
+			}
+		})
+	}
+}
+
+func TestLookupCopies(t *testing.T) {
+	raw := writeBundle(t, nil, map[string]map[string]string{"en": {"a": "hello"}}, bundle.CompressionNone)
+	b, err := bundle.Open(raw)
+	if err != nil {
+		t.Fatalf("Open() err:%v", err)
+	}
+	value, _ := b.Lookup("en", "a")
+	table, _ := b.Table("en")
+	aliased, _ := table.LookupBytes("a")
+	aliased[0] = 'j'
+	if value != "hello" {
+		t.Errorf("Lookup() = %q, changed with the bundle data", value)
+	}
+	if v, _ := table.Lookup("a"); v != "jello" {
+		t.Errorf("LookupBytes() = %q, want it to alias the bundle data", v)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	raw := writeBundle(t, nil, testData(1, 10), bundle.CompressionZstd)
+	for _, v := range [][]byte{nil, []byte("I18B"), raw[:len(raw)/2]} {
+		if b, err := bundle.Open(v); err == nil {
+			if _, err = b.Table("lang00"); err == nil {
+				t.Errorf("Open(%d bytes) accepted a broken bundle", len(v))
+			}
+		}
+	}
+}
+
+func TestHostileRawSize(t *testing.T) {
+	for _, c := range []bundle.Compression{bundle.CompressionDeflate, bundle.CompressionZstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			raw := writeBundle(t, nil, map[string]map[string]string{"en": {"a": "hello"}}, c)
+			// raw size of the only directory entry, after the header and the
+			// name length, name, offset and stored size
+			binary.LittleEndian.PutUint32(raw[16+2+len("en")+8:], math.MaxUint32)
+			b, err := bundle.Open(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			_, err = b.Table("en")
+			runtime.ReadMemStats(&after)
+			if err == nil {
+				t.Error("Table() accepted a table shorter than its raw size")
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+				t.Errorf("Table() allocated %d bytes for a %d bytes bundle", allocated, len(raw))
+			}
+		})
+	}
+}
+
+const (
+	benchLanguages = 40
+	benchKeys      = 2000
+)
+
+// benchInput returns the json and bundles of the same data
+func benchInput(b *testing.B) (rawJSON []byte, bundles map[bundle.Compression][]byte) {
+	meta := &bundle.Metadata{Version: "bench"}
+	data := testData(benchLanguages, benchKeys)
+	rawJSON, err := json.Marshal(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bundles = make(map[bundle.Compression][]byte)
+	for _, c := range compressions {
+		bundles[c] = writeBundle(b, meta, data, c)
+	}
+	return
+}
+
+// BenchmarkLoad opens the strings and looks up a key, what a client does at
+// startup
+func BenchmarkLoad(b *testing.B) {
+	rawJSON, bundles := benchInput(b)
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ReportMetric(float64(len(rawJSON)), "file-bytes")
+		for i := 0; i < b.N; i++ {
+			var out map[string]map[string]string
+			if err := json.Unmarshal(rawJSON, &out); err != nil {
+				b.Fatal(err)
+			}
+			if _, ok := out["lang00"]["string_key_0"]; !ok {
+				b.Fatal("key not found")
+			}
+		}
+	})
+	for _, c := range compressions {
+		raw := bundles[c]
+		b.Run("bundle-"+c.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ReportMetric(float64(len(raw)), "file-bytes")
+			for i := 0; i < b.N; i++ {
+				bd, err := bundle.Open(raw)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, ok := bd.Lookup("lang00", "string_key_0"); !ok {
+					b.Fatal("key not found")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLookup looks up a key of loaded strings
+func BenchmarkLookup(b *testing.B) {
+	rawJSON, bundles := benchInput(b)
+
+	var out map[string]map[string]string
+	if err := json.Unmarshal(rawJSON, &out); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		kvs := out["lang00"]
+		for i := 0; i < b.N; i++ {
+			if _, ok := kvs["string_key_1234"]; !ok {
+				b.Fatal("key not found")
+			}
+		}
+	})
+
+	bd, err := bundle.Open(bundles[bundle.CompressionNone])
+	if err != nil {
+		b.Fatal(err)
+	}
+	table, err := bd.Table("lang00")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("bundle", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, ok := table.Lookup("string_key_1234"); !ok {
+				b.Fatal("key not found")
+			}
+		}
+	})
+	b.Run("bundle-bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, ok := table.LookupBytes("string_key_1234"); !ok {
+				b.Fatal("key not found")
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// Package bundle reads the compact binary string bundles written by i18n.
+//
+// A bundle is laid out as follows, all integers are little endian:
+//
+//	header     magic "I18B", version uint16, compression uint8, reserved uint8,
+//	           metadata length uint32, language count uint32
+//	metadata   JSON encoded Metadata
+//	directory  per language: name length uint16, name, section offset uint32,
+//	           stored size uint32, raw size uint32
+//	sections   per language string table, compressed as a whole if required
+//
+// A string table holds an entry count uint32, the entries sorted by key hash
+// (hash uint64, key offset uint32, key length uint32, value offset uint32,
+// value length uint32) and a blob with all keys and values, offsets are
+// relative to the start of the blob.
+package bundle
+
+import "fmt"
+
+// Magic identifies a bundle file
+const Magic = "I18B"
+
+// Version of the bundle format
+const Version uint16 = 1
+
+const (
+	headerSize    = 16
+	dirEntryFixed = 2 + 4 + 4 + 4
+	tableHeader   = 4
+	entrySize     = 8 + 4 + 4 + 4 + 4
+)
+
+// Compression algorithm applied to string tables
+type Compression uint8
+
+const (
+	CompressionNone Compression = iota
+	CompressionDeflate
+	CompressionZstd
+)
+
+var compressionNames = map[Compression]string{
+	CompressionNone:    "none",
+	CompressionDeflate: "deflate",
+	CompressionZstd:    "zstd",
+}
+
+func (c Compression) String() string {
+	if name, ok := compressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("compression(%d)", uint8(c))
+}
+
+// ParseCompression converts a compression name to its Compression value
+func ParseCompression(name string) (Compression, error) {
+	if name == "" {
+		return CompressionNone, nil
+	}
+	for c, n := range compressionNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return CompressionNone, fmt.Errorf("unknown compression '%v'", name)
+}
+
+// Metadata carried in the bundle header
+type Metadata struct {
+	Version     string   `json:"tool_version"`
+	SourceFiles []string `json:"source_files"`
+	CreatedAt   string   `json:"created_at"`
+	ModifiedAt  string   `json:"modified_at"`
+}
+
+// Hash returns the 64-bit FNV-1a hash used to index keys
+func Hash(key string) uint64 {
+	const offset64 = 14695981039346656037
+	const prime64 = 1099511628211
+
+	h := uint64(offset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime64
+	}
+	return h
+}
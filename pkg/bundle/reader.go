@@ -0,0 +1,291 @@
+package bundle
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrInvalidBundle is returned when the data is not a valid bundle
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+type section struct {
+	offset  uint32
+	size    uint32
+	rawSize uint32
+	table   *Table
+	err     error
+	once    sync.Once
+}
+
+// Bundle is a loaded string bundle
+type Bundle struct {
+	data        []byte
+	compression Compression
+	meta        *Metadata
+	languages   []string
+	sections    map[string]*section
+}
+
+// Open parses a bundle from data without copying it, string tables of an
+// uncompressed bundle point directly into data, so it must not be modified
+// while the bundle is in use
+func Open(data []byte) (b *Bundle, err error) {
+	if len(data) < headerSize || string(data[:4]) != Magic {
+		err = ErrInvalidBundle
+		return
+	}
+
+	le := binary.LittleEndian
+	if v := le.Uint16(data[4:]); v != Version {
+		err = fmt.Errorf("unsupported bundle version %d", v)
+		return
+	}
+
+	tmp := &Bundle{
+		data:        data,
+		compression: Compression(data[6]),
+		sections:    make(map[string]*section),
+	}
+	if _, ok := compressionNames[tmp.compression]; !ok {
+		err = fmt.Errorf("unsupported bundle %v", tmp.compression)
+		return
+	}
+
+	metaLen := int(le.Uint32(data[8:]))
+	langCount := int(le.Uint32(data[12:]))
+	pos := headerSize
+	if metaLen > len(data)-pos {
+		err = ErrInvalidBundle
+		return
+	}
+	if metaLen > 0 {
+		tmp.meta = &Metadata{}
+		if err = json.Unmarshal(data[pos:pos+metaLen], tmp.meta); err != nil {
+			return
+		}
+	}
+	pos += metaLen
+
+	for i := 0; i < langCount; i++ {
+		if len(data)-pos < dirEntryFixed {
+			err = ErrInvalidBundle
+			return
+		}
+		nameLen := int(le.Uint16(data[pos:]))
+		pos += 2
+		if len(data)-pos < nameLen+12 {
+			err = ErrInvalidBundle
+			return
+		}
+		lang := string(data[pos : pos+nameLen])
+		pos += nameLen
+		s := &section{
+			offset:  le.Uint32(data[pos:]),
+			size:    le.Uint32(data[pos+4:]),
+			rawSize: le.Uint32(data[pos+8:]),
+		}
+		pos += 12
+		if uint64(s.offset)+uint64(s.size) > uint64(len(data)) {
+			err = ErrInvalidBundle
+			return
+		}
+		tmp.languages = append(tmp.languages, lang)
+		tmp.sections[lang] = s
+	}
+
+	sort.Strings(tmp.languages)
+	b = tmp
+
+	return
+}
+
+// ReadFile loads a bundle file into memory and opens it
+func ReadFile(path string) (*Bundle, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Open(raw)
+}
+
+// Metadata returns the metadata stored in the header, nil if there is none
+func (b *Bundle) Metadata() *Metadata {
+	return b.meta
+}
+
+// Compression returns the compression applied to the string tables
+func (b *Bundle) Compression() Compression {
+	return b.compression
+}
+
+// Languages returns all languages in the bundle, sorted
+func (b *Bundle) Languages() []string {
+	return b.languages
+}
+
+// Table returns the string table of a language, compressed tables are
+// inflated on first access
+func (b *Bundle) Table(lang string) (*Table, error) {
+	s, ok := b.sections[lang]
+	if !ok {
+		return nil, fmt.Errorf("language '%v' not found in bundle", lang)
+	}
+
+	s.once.Do(func() {
+		raw := b.data[s.offset : s.offset+s.size]
+		raw, s.err = decompress(b.compression, raw, int(s.rawSize))
+		if s.err == nil {
+			s.table, s.err = openTable(raw)
+		}
+	})
+
+	return s.table, s.err
+}
+
+// Lookup is a shortcut for Table(lang).Lookup(key)
+func (b *Bundle) Lookup(lang, key string) (string, bool) {
+	t, err := b.Table(lang)
+	if err != nil {
+		return "", false
+	}
+	return t.Lookup(key)
+}
+
+// maxPrealloc caps the buffer allocated up front for an inflated table, the
+// raw size comes from the file and is not trusted before that much data has
+// actually been inflated
+const maxPrealloc = 1 << 20
+
+func decompress(c Compression, raw []byte, rawSize int) (out []byte, err error) {
+	switch c {
+	case CompressionNone:
+		out = raw
+	case CompressionDeflate:
+		r := flate.NewReader(bytes.NewReader(raw))
+		out, err = inflate(r, rawSize)
+		if err == nil {
+			err = r.Close()
+		}
+	case CompressionZstd:
+		var d *zstd.Decoder
+		d, err = zstd.NewReader(bytes.NewReader(raw), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return
+		}
+		defer d.Close()
+		out, err = inflate(d, rawSize)
+	default:
+		err = fmt.Errorf("unsupported bundle %v", c)
+	}
+	if err == nil && len(out) != rawSize {
+		err = ErrInvalidBundle
+	}
+	return
+}
+
+// inflate reads r up to one byte past rawSize, enough to tell a table larger
+// than announced, the buffer grows as data arrives
+func inflate(r io.Reader, rawSize int) ([]byte, error) {
+	size := rawSize
+	if size > maxPrealloc {
+		size = maxPrealloc
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	_, err := buf.ReadFrom(io.LimitReader(r, int64(rawSize)+1))
+	return buf.Bytes(), err
+}
+
+// Table is the string table of a single language
+type Table struct {
+	count   int
+	entries []byte
+	blob    []byte
+}
+
+func openTable(raw []byte) (*Table, error) {
+	if len(raw) < tableHeader {
+		return nil, ErrInvalidBundle
+	}
+	count := int(binary.LittleEndian.Uint32(raw))
+	end := tableHeader + count*entrySize
+	if count < 0 || end > len(raw) {
+		return nil, ErrInvalidBundle
+	}
+
+	return &Table{
+		count:   count,
+		entries: raw[tableHeader:end],
+		blob:    raw[end:],
+	}, nil
+}
+
+// Len returns the number of strings in the table
+func (t *Table) Len() int {
+	return t.count
+}
+
+func (t *Table) entry(i int) (hash uint64, key, value []byte) {
+	le := binary.LittleEndian
+	e := t.entries[i*entrySize:]
+	hash = le.Uint64(e)
+	keyOff, keyLen := le.Uint32(e[8:]), le.Uint32(e[12:])
+	valOff, valLen := le.Uint32(e[16:]), le.Uint32(e[20:])
+	if uint64(keyOff)+uint64(keyLen) <= uint64(len(t.blob)) {
+		key = t.blob[keyOff : keyOff+keyLen]
+	}
+	if uint64(valOff)+uint64(valLen) <= uint64(len(t.blob)) {
+		value = t.blob[valOff : valOff+valLen]
+	}
+	return
+}
+
+// LookupBytes returns the value of key without copying it. The slice
+// aliases the table memory: the data passed to Open for an uncompressed
+// bundle, the inflated table otherwise. It must not be modified, and it
+// keeps that memory alive
+func (t *Table) LookupBytes(key string) ([]byte, bool) {
+	h := Hash(key)
+	i := sort.Search(t.count, func(i int) bool {
+		return binary.LittleEndian.Uint64(t.entries[i*entrySize:]) >= h
+	})
+	for ; i < t.count; i++ {
+		eh, k, v := t.entry(i)
+		if eh != h {
+			break
+		}
+		if string(k) == key {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns a copy of the value of key, see LookupBytes to avoid the
+// copy
+func (t *Table) Lookup(key string) (string, bool) {
+	v, ok := t.LookupBytes(key)
+	if !ok {
+		return "", false
+	}
+	return string(v), true
+}
+
+// Range calls fn for every key value pair in the table until fn returns false
+func (t *Table) Range(fn func(key, value []byte) bool) {
+	for i := 0; i < t.count; i++ {
+		_, k, v := t.entry(i)
+		if !fn(k, v) {
+			return
+		}
+	}
+}
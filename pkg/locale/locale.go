@@ -0,0 +1,254 @@
+// Package locale parses and normalizes BCP-47 language tags and converts
+// them to and from the names platforms use for their localized resources:
+//
+//	BCP-47              zh-TW, sr-Latn, es-419
+//	Android qualifier   zh-rTW, b+sr+Latn, b+es+419
+//	iOS                 zh-TW.lproj, sr-Latn.lproj, es-419.lproj
+//	Java/Chrome         zh_TW, sr_Latn, es_419
+//
+// Legacy language codes (in, iw, ji, ...) are normalized to their current
+// ones, so values-iw and a "he" column refer to the same locale.
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmpty is returned when parsing an empty tag
+var ErrEmpty = errors.New("empty locale")
+
+// LprojSuffix is the suffix of iOS localization folders
+const LprojSuffix = ".lproj"
+
+// legacy language codes and their current replacements
+var legacyCodes = map[string]string{
+	"in": "id",
+	"iw": "he",
+	"ji": "yi",
+	"jw": "jv",
+	"mo": "ro",
+}
+
+// Android resolves these languages with their legacy codes
+var androidLegacyCodes = map[string]string{
+	"id": "in",
+	"he": "iw",
+	"yi": "ji",
+}
+
+// Locale is a normalized BCP-47 language tag
+type Locale struct {
+	// Language is the lowercase ISO 639 language code
+	Language string
+	// Script is the titlecase ISO 15924 script code, e.g. Latn
+	Script string
+	// Region is the uppercase ISO 3166 region code or UN M.49 area code
+	Region string
+	// Variants are the lowercase registered variants
+	Variants []string
+	// Extension holds any extension and private use subtags, lowercase
+	Extension string
+}
+
+// Parse parses a BCP-47 tag, an Android resource qualifier, an iOS .lproj
+// folder name or an underscore separated Java/Chrome locale
+func Parse(tag string) (l Locale, err error) {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimSuffix(tag, LprojSuffix)
+	if tag == "" {
+		err = ErrEmpty
+		return
+	}
+
+	var subtags []string
+	if strings.HasPrefix(tag, "b+") {
+		subtags = strings.Split(tag[2:], "+")
+	} else {
+		subtags = strings.FieldsFunc(tag, func(r rune) bool {
+			return r == '-' || r == '_'
+		})
+	}
+
+	if len(subtags) == 0 {
+		err = fmt.Errorf("invalid locale %q", tag)
+		return
+	}
+
+	language := strings.ToLower(subtags[0])
+	if !isAlpha(language) || len(language) < 2 || len(language) > 3 {
+		err = fmt.Errorf("invalid language %q in locale %q", subtags[0], tag)
+		return
+	}
+	if v, ok := legacyCodes[language]; ok {
+		language = v
+	}
+	l.Language = language
+
+	rest := subtags[1:]
+	for i, s := range rest {
+		switch {
+		case len(s) == 1:
+			// extension singleton, keep the remaining subtags verbatim
+			l.Extension = strings.ToLower(strings.Join(rest[i:], "-"))
+			return
+		case l.Script == "" && l.Region == "" && len(l.Variants) == 0 && isScript(s):
+			l.Script = strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+		case l.Region == "" && len(l.Variants) == 0 && isRegion(s):
+			l.Region = strings.ToUpper(s)
+		case l.Region == "" && len(l.Variants) == 0 && isAndroidRegion(s):
+			// android qualifier, e.g. zh-rTW
+			l.Region = strings.ToUpper(s[1:])
+		case isVariant(s):
+			l.Variants = append(l.Variants, strings.ToLower(s))
+		case l.Script == "" && strings.HasPrefix(s, "#") && isScript(s[1:]):
+			// java form, e.g. sr_RS_#Latn
+			l.Script = strings.ToUpper(s[1:2]) + strings.ToLower(s[2:])
+		default:
+			return Locale{}, fmt.Errorf("invalid subtag %q in locale %q", s, tag)
+		}
+	}
+
+	return
+}
+
+// MustParse is like Parse but panics if tag cannot be parsed
+func MustParse(tag string) Locale {
+	l, err := Parse(tag)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Normalize returns the BCP-47 form of tag, or tag itself if it cannot be
+// parsed
+func Normalize(tag string) string {
+	l, err := Parse(tag)
+	if err != nil {
+		return tag
+	}
+	return l.String()
+}
+
+// IsZero reports whether l is the zero Locale
+func (l Locale) IsZero() bool {
+	return l.Language == ""
+}
+
+func (l Locale) subtags() []string {
+	subtags := []string{l.Language}
+	if l.Script != "" {
+		subtags = append(subtags, l.Script)
+	}
+	if l.Region != "" {
+		subtags = append(subtags, l.Region)
+	}
+	subtags = append(subtags, l.Variants...)
+	if l.Extension != "" {
+		subtags = append(subtags, l.Extension)
+	}
+	return subtags
+}
+
+// String returns the BCP-47 tag, e.g. zh-Hant-TW
+func (l Locale) String() string {
+	if l.IsZero() {
+		return ""
+	}
+	return strings.Join(l.subtags(), "-")
+}
+
+// Base returns the locale with language only
+func (l Locale) Base() Locale {
+	return Locale{Language: l.Language}
+}
+
+// AndroidQualifier returns the resource qualifier used in values-* folder
+// names, e.g. zh-rTW, or b+sr+Latn for tags the legacy form cannot express
+func (l Locale) AndroidQualifier() string {
+	if l.IsZero() {
+		return ""
+	}
+	language := l.Language
+	if v, ok := androidLegacyCodes[language]; ok {
+		language = v
+	}
+	if l.Script == "" && len(l.Variants) == 0 && l.Extension == "" && (l.Region == "" || isAlpha(l.Region)) {
+		if len(language) == 2 {
+			if l.Region == "" {
+				return language
+			}
+			return language + "-r" + l.Region
+		}
+	}
+	subtags := l.subtags()
+	subtags[0] = language
+	if l.Extension != "" {
+		subtags[len(subtags)-1] = strings.ReplaceAll(l.Extension, "-", "+")
+	}
+	return "b+" + strings.Join(subtags, "+")
+}
+
+// Lproj returns the iOS localization folder name, e.g. zh-Hant.lproj
+func (l Locale) Lproj() string {
+	if l.IsZero() {
+		return ""
+	}
+	return l.String() + LprojSuffix
+}
+
+// Underscore returns the Java/Chrome form, e.g. zh_TW
+func (l Locale) Underscore() string {
+	if l.IsZero() {
+		return ""
+	}
+	return strings.ReplaceAll(l.String(), "-", "_")
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isDigit(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isScript(s string) bool {
+	return len(s) == 4 && isAlpha(s)
+}
+
+func isRegion(s string) bool {
+	return (len(s) == 2 && isAlpha(s)) || (len(s) == 3 && isDigit(s))
+}
+
+func isAndroidRegion(s string) bool {
+	return len(s) == 3 && (s[0] == 'r' || s[0] == 'R') && isAlpha(s[1:])
+}
+
+func isVariant(s string) bool {
+	if !isAlnum(s) {
+		return false
+	}
+	return (len(s) >= 5 && len(s) <= 8) || (len(s) == 4 && isDigit(s[:1]))
+}
@@ -0,0 +1,105 @@
+package locale
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		android string
+		lproj   string
+		wantErr bool
+	}{
+		{tag: "en", want: "en", android: "en", lproj: "en.lproj"},
+		{tag: " EN ", want: "en", android: "en", lproj: "en.lproj"},
+		{tag: "zh-TW", want: "zh-TW", android: "zh-rTW", lproj: "zh-TW.lproj"},
+		{tag: "zh-rTW", want: "zh-TW", android: "zh-rTW", lproj: "zh-TW.lproj"},
+		{tag: "zh_TW", want: "zh-TW", android: "zh-rTW", lproj: "zh-TW.lproj"},
+		{tag: "zh-TW.lproj", want: "zh-TW", android: "zh-rTW", lproj: "zh-TW.lproj"},
+		{tag: "ZH-hant-tw", want: "zh-Hant-TW", android: "b+zh+Hant+TW", lproj: "zh-Hant-TW.lproj"},
+		{tag: "b+sr+Latn", want: "sr-Latn", android: "b+sr+Latn", lproj: "sr-Latn.lproj"},
+		{tag: "sr_RS_#Latn", want: "sr-Latn-RS", android: "b+sr+Latn+RS", lproj: "sr-Latn-RS.lproj"},
+		{tag: "es-419", want: "es-419", android: "b+es+419", lproj: "es-419.lproj"},
+		{tag: "b+es+419", want: "es-419", android: "b+es+419", lproj: "es-419.lproj"},
+		{tag: "fil", want: "fil", android: "b+fil", lproj: "fil.lproj"},
+		{tag: "fil-PH", want: "fil-PH", android: "b+fil+PH", lproj: "fil-PH.lproj"},
+		{tag: "de-CH-1901", want: "de-CH-1901", android: "b+de+CH+1901", lproj: "de-CH-1901.lproj"},
+		{tag: "en-US-x-Foo", want: "en-US-x-foo", android: "b+en+US+x+foo", lproj: "en-US-x-foo.lproj"},
+		// legacy codes are normalized, android still resolves some of them
+		{tag: "iw", want: "he", android: "iw", lproj: "he.lproj"},
+		{tag: "he", want: "he", android: "iw", lproj: "he.lproj"},
+		{tag: "iw-rIL", want: "he-IL", android: "iw-rIL", lproj: "he-IL.lproj"},
+		{tag: "in", want: "id", android: "in", lproj: "id.lproj"},
+		{tag: "in_ID", want: "id-ID", android: "in-rID", lproj: "id-ID.lproj"},
+		{tag: "ji", want: "yi", android: "ji", lproj: "yi.lproj"},
+		{tag: "mo", want: "ro", android: "ro", lproj: "ro.lproj"},
+		{tag: "", wantErr: true},
+		{tag: ".lproj", wantErr: true},
+		{tag: "b+", wantErr: true},
+		{tag: "e", wantErr: true},
+		{tag: "english", wantErr: true},
+		{tag: "e1", wantErr: true},
+		{tag: "en-US-!!", wantErr: true},
+		{tag: "en-Latn-Latn", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			l, err := Parse(tt.tag)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse(%q) = %v, want error", tt.tag, l)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) err:%v", tt.tag, err)
+			}
+			if got := l.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.tag, got, tt.want)
+			}
+			if got := l.AndroidQualifier(); got != tt.android {
+				t.Errorf("Parse(%q).AndroidQualifier() = %v, want %v", tt.tag, got, tt.android)
+			}
+			if got := l.Lproj(); got != tt.lproj {
+				t.Errorf("Parse(%q).Lproj() = %v, want %v", tt.tag, got, tt.lproj)
+			}
+
+			// every form parses back to the same locale
+			for _, form := range []string{l.AndroidQualifier(), l.Lproj(), l.Underscore()} {
+				back, err := Parse(form)
+				if err != nil {
+					t.Errorf("Parse(%q) err:%v", form, err)
+					continue
+				}
+				if back.String() != l.String() {
+					t.Errorf("Parse(%q) = %v, want %v", form, back, l)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "zh_rCN", want: "zh-CN"},
+		{tag: "in", want: "id"},
+		{tag: "English", want: "English"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.tag); got != tt.want {
+			t.Errorf("Normalize(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestZero(t *testing.T) {
+	var l Locale
+	if !l.IsZero() || l.String() != "" || l.AndroidQualifier() != "" || l.Lproj() != "" || l.Underscore() != "" {
+		t.Errorf("zero locale has forms %q %q %q %q", l.String(), l.AndroidQualifier(), l.Lproj(), l.Underscore())
+	}
+}
@@ -0,0 +1,169 @@
+package locale
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// languageNames holds the names of common languages in English, Chinese
+// (simplified and traditional), Japanese, Korean, Spanish, French, German,
+// Portuguese, Russian and their own language
+var languageNames = []struct {
+	tag   string
+	names []string
+}{
+	{"en", []string{"English", "英语", "英文", "英語", "영어", "Inglés", "Anglais", "Englisch", "Inglês", "Английский"}},
+	{"en-US", []string{"English (US)", "American English", "美式英语", "美国英语", "美式英語", "美國英語", "アメリカ英語", "미국 영어"}},
+	{"en-GB", []string{"English (UK)", "British English", "英式英语", "英国英语", "英式英語", "英國英語", "イギリス英語", "영국 영어"}},
+	{"zh", []string{"Chinese", "中文", "汉语", "漢語", "中国語", "중국어", "Chino", "Chinois", "Chinesisch", "Chinês", "Китайский"}},
+	{"zh-CN", []string{"Simplified Chinese", "Chinese (Simplified)", "简体中文", "简中", "简体", "中文简体", "中文(简体)", "簡體中文", "簡体中国語", "中国語(簡体字)", "중국어 간체", "Chino simplificado", "Chinois simplifié", "Vereinfachtes Chinesisch", "Chinês simplificado", "Китайский упрощённый"}},
+	{"zh-TW", []string{"Traditional Chinese", "Chinese (Traditional)", "繁体中文", "繁中", "繁体", "中文繁体", "中文(繁体)", "繁體中文", "繁體", "中文繁體", "中文(繁體)", "繁体中国語", "中国語(繁体字)", "중국어 번체", "Chino tradicional", "Chinois traditionnel", "Traditionelles Chinesisch", "Chinês tradicional", "Китайский традиционный"}},
+	{"zh-HK", []string{"Chinese (Hong Kong)", "Cantonese", "香港繁体", "粤语", "香港繁體", "粵語", "廣東話", "広東語", "광둥어"}},
+	{"ja", []string{"Japanese", "日语", "日文", "日語", "日本語", "일본어", "Japonés", "Japonais", "Japanisch", "Japonês", "Японский"}},
+	{"ko", []string{"Korean", "韩语", "韩文", "韓語", "韓文", "韓国語", "한국어", "Coreano", "Coréen", "Koreanisch", "Корейский"}},
+	{"es", []string{"Spanish", "西班牙语", "西语", "西班牙語", "西語", "スペイン語", "스페인어", "Español", "Espagnol", "Spanisch", "Espanhol", "Испанский"}},
+	{"es-419", []string{"Spanish (Latin America)", "Latin American Spanish", "拉美西班牙语", "拉美西语", "拉美西班牙語", "ラテンアメリカスペイン語", "중남미 스페인어", "Español latinoamericano"}},
+	{"fr", []string{"French", "法语", "法文", "法語", "フランス語", "프랑스어", "Francés", "Français", "Französisch", "Francês", "Французский"}},
+	{"de", []string{"German", "德语", "德文", "德語", "ドイツ語", "독일어", "Alemán", "Allemand", "Deutsch", "Alemão", "Немецкий"}},
+	{"it", []string{"Italian", "意大利语", "意语", "義大利語", "意大利語", "イタリア語", "이탈리아어", "Italiano", "Italien", "Italienisch", "Итальянский"}},
+	{"pt", []string{"Portuguese", "葡萄牙语", "葡语", "葡萄牙語", "葡語", "ポルトガル語", "포르투갈어", "Portugués", "Portugais", "Portugiesisch", "Português", "Португальский"}},
+	{"pt-BR", []string{"Portuguese (Brazil)", "Brazilian Portuguese", "巴西葡萄牙语", "巴葡", "巴西葡萄牙語", "ブラジルポルトガル語", "브라질 포르투갈어", "Português do Brasil", "Portugués de Brasil"}},
+	{"ru", []string{"Russian", "俄语", "俄文", "俄語", "ロシア語", "러시아어", "Ruso", "Russe", "Russisch", "Russo", "Русский"}},
+	{"ar", []string{"Arabic", "阿拉伯语", "阿语", "阿拉伯語", "アラビア語", "아랍어", "Árabe", "Arabe", "Arabisch", "Арабский", "العربية"}},
+	{"th", []string{"Thai", "泰语", "泰文", "泰語", "タイ語", "태국어", "Tailandés", "Thaï", "Thailändisch", "Tailandês", "Тайский", "ไทย", "ภาษาไทย"}},
+	{"vi", []string{"Vietnamese", "越南语", "越语", "越南語", "ベトナム語", "베트남어", "Vietnamita", "Vietnamien", "Vietnamesisch", "Вьетнамский", "Tiếng Việt"}},
+	{"id", []string{"Indonesian", "印尼语", "印度尼西亚语", "印尼語", "印度尼西亞語", "インドネシア語", "인도네시아어", "Indonesio", "Indonésien", "Indonesisch", "Indonésio", "Индонезийский", "Bahasa Indonesia"}},
+	{"ms", []string{"Malay", "马来语", "馬來語", "マレー語", "말레이어", "Malayo", "Malais", "Malaiisch", "Malaio", "Малайский", "Bahasa Melayu"}},
+	{"tr", []string{"Turkish", "土耳其语", "土语", "土耳其語", "トルコ語", "터키어", "Turco", "Turc", "Türkisch", "Турецкий", "Türkçe"}},
+	{"hi", []string{"Hindi", "印地语", "印地語", "ヒンディー語", "힌디어", "Hindí", "Хинди", "हिन्दी"}},
+	{"nl", []string{"Dutch", "荷兰语", "荷兰文", "荷蘭語", "オランダ語", "네덜란드어", "Neerlandés", "Néerlandais", "Niederländisch", "Holandês", "Нидерландский", "Nederlands"}},
+	{"pl", []string{"Polish", "波兰语", "波蘭語", "ポーランド語", "폴란드어", "Polaco", "Polonais", "Polnisch", "Polonês", "Польский", "Polski"}},
+	{"uk", []string{"Ukrainian", "乌克兰语", "烏克蘭語", "ウクライナ語", "우크라이나어", "Ucraniano", "Ukrainien", "Ukrainisch", "Украинский", "Українська"}},
+	{"he", []string{"Hebrew", "希伯来语", "希伯來語", "ヘブライ語", "히브리어", "Hebreo", "Hébreu", "Hebräisch", "Hebraico", "Иврит", "עברית"}},
+	{"fa", []string{"Persian", "Farsi", "波斯语", "波斯語", "ペルシア語", "페르시아어", "Persa", "Persan", "Persisch", "Персидский", "فارسی"}},
+	{"sv", []string{"Swedish", "瑞典语", "瑞典語", "スウェーデン語", "스웨덴어", "Sueco", "Suédois", "Schwedisch", "Шведский", "Svenska"}},
+	{"da", []string{"Danish", "丹麦语", "丹麥語", "デンマーク語", "덴마크어", "Danés", "Danois", "Dänisch", "Dinamarquês", "Датский", "Dansk"}},
+	{"nb", []string{"Norwegian", "挪威语", "挪威語", "ノルウェー語", "노르웨이어", "Noruego", "Norvégien", "Norwegisch", "Norueguês", "Норвежский", "Norsk"}},
+	{"fi", []string{"Finnish", "芬兰语", "芬蘭語", "フィンランド語", "핀란드어", "Finlandés", "Finnois", "Finnisch", "Finlandês", "Финский", "Suomi"}},
+	{"cs", []string{"Czech", "捷克语", "捷克語", "チェコ語", "체코어", "Checo", "Tchèque", "Tschechisch", "Tcheco", "Чешский", "Čeština"}},
+	{"el", []string{"Greek", "希腊语", "希臘語", "ギリシャ語", "그리스어", "Griego", "Grec", "Griechisch", "Grego", "Греческий", "Ελληνικά"}},
+	{"hu", []string{"Hungarian", "匈牙利语", "匈牙利語", "ハンガリー語", "헝가리어", "Húngaro", "Hongrois", "Ungarisch", "Венгерский", "Magyar"}},
+	{"ro", []string{"Romanian", "罗马尼亚语", "羅馬尼亞語", "ルーマニア語", "루마니아어", "Rumano", "Roumain", "Rumänisch", "Romeno", "Румынский", "Română"}},
+	{"fil", []string{"Filipino", "Tagalog", "菲律宾语", "他加禄语", "菲律賓語", "フィリピン語", "필리핀어"}},
+	{"bn", []string{"Bengali", "孟加拉语", "孟加拉語", "ベンガル語", "벵골어", "বাংলা"}},
+}
+
+var nameToTag map[string]string
+
+func init() {
+	nameToTag = make(map[string]string)
+	for _, entry := range languageNames {
+		for _, name := range entry.names {
+			nameToTag[nameKey(name)] = entry.tag
+		}
+	}
+}
+
+// nameKey folds case, spaces and brackets of a language name
+func nameKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-', '(', ')', '（', '）':
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// LookupName returns the locale of a human-readable language name like
+// "Traditional Chinese" or "繁体中文", names without a script or region like
+// "中文" give the bare language
+func LookupName(name string) (l Locale, ok bool) {
+	var tag string
+	tag, ok = nameToTag[nameKey(name)]
+	if !ok {
+		return
+	}
+	return MustParse(tag), true
+}
+
+// SuggestNames returns up to n known language names close to name, each
+// followed by its tag, e.g. "繁体中文 (zh-TW)"
+func SuggestNames(name string, n int) []string {
+	key := nameKey(name)
+	if key == "" {
+		return nil
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	threshold := utf8.RuneCountInString(key) / 2
+	if threshold < 1 {
+		threshold = 1
+	}
+	for _, entry := range languageNames {
+		for _, v := range entry.names {
+			k := nameKey(v)
+			d := levenshtein(key, k)
+			if strings.Contains(k, key) || strings.Contains(key, k) {
+				d = 0
+			}
+			if d > threshold {
+				continue
+			}
+			suggestion := v + " (" + entry.tag + ")"
+			if seen[suggestion] {
+				continue
+			}
+			seen[suggestion] = true
+			candidates = append(candidates, candidate{name: suggestion, distance: d})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < n; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance of two strings in runes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "English", want: "en"},
+		{name: " english ", want: "en"},
+		{name: "中文", want: "zh"},
+		{name: "Chinese", want: "zh"},
+		{name: "简体中文", want: "zh-CN"},
+		{name: "Simplified Chinese", want: "zh-CN"},
+		{name: "chinese (simplified)", want: "zh-CN"},
+		{name: "中文（繁體）", want: "zh-TW"},
+		{name: "繁体中文", want: "zh-TW"},
+		{name: "粤语", want: "zh-HK"},
+		{name: "Latin_American-Spanish", want: "es-419"},
+		{name: "Bahasa Indonesia", want: "id"},
+		{name: "Klingon"},
+		{name: ""},
+	}
+	for _, tt := range tests {
+		l, ok := LookupName(tt.name)
+		if ok != (tt.want != "") || (ok && l.String() != tt.want) {
+			t.Errorf("LookupName(%q) = %v, %v, want %q", tt.name, l, ok, tt.want)
+		}
+	}
+
+	// the bare language matches values-zh
+	if l, _ := LookupName("中文"); l.AndroidQualifier() != "zh" {
+		t.Errorf("LookupName(中文) qualifier = %v, want zh", l.AndroidQualifier())
+	}
+}
+
+func TestSuggestNames(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want string
+	}{
+		{name: "Englsh", n: 1, want: "English (en)"},
+		{name: "Portugese", n: 1, want: "Portuguese (pt)"},
+		{name: "Japanse", n: 1, want: "Japanese (ja)"},
+		{name: "Frnch", n: 3, want: "French (fr)"},
+		{name: "xyzzy", n: 3},
+		{name: "  ", n: 3},
+		{name: "English", n: 0},
+	}
+	for _, tt := range tests {
+		got := strings.Join(SuggestNames(tt.name, tt.n), ",")
+		if tt.want == "" && got != "" {
+			t.Errorf("SuggestNames(%q, %d) = %v, want none", tt.name, tt.n, got)
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("SuggestNames(%q, %d) = %v, want %v first", tt.name, tt.n, got, tt.want)
+		}
+		if n := len(SuggestNames(tt.name, tt.n)); n > tt.n {
+			t.Errorf("SuggestNames(%q, %d) returns %d names", tt.name, tt.n, n)
+		}
+	}
+}
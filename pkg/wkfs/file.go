@@ -3,25 +3,28 @@ package wkfs
 import (
 	"bufio"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
-// FileExists returns true if file is exists
+// FileExists returns true if file is exists, false if it cannot be stat
 func FileExists(path string) bool {
 	info, err := os.Stat(path)
-	if os.IsNotExist(err) {
+	if err != nil {
 		return false
 	}
 
 	return !info.IsDir()
 }
 
+// IsFile returns true if path is a regular file, empty ones included
 func IsFile(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
 		return false
 	}
 
-	return info.Size() > 0 && info.Mode().IsRegular()
+	return info.Mode().IsRegular()
 }
 
 func ReadAllLines(path string) ([]string, error) {
@@ -44,3 +47,58 @@ func ReadAllLines(path string) ([]string, error) {
 
 	return lines, err
 }
+
+// WriteFiles writes a set of files, contents are staged to temporary files
+// next to their targets first and only renamed into place once all of them
+// are written, so a failed staging leaves the targets untouched. Existing
+// targets keep their mode, new ones are created 0644. Targets are
+// renamed in path order and not rolled back, a failed rename leaves the
+// targets before it replaced
+func WriteFiles(files map[string][]byte) (err error) {
+	staged := make(map[string]string, len(files))
+	defer func() {
+		if err != nil {
+			for _, tmp := range staged {
+				_ = os.Remove(tmp)
+			}
+		}
+	}()
+
+	for path, content := range files {
+		err = EnsureDir(filepath.Dir(path))
+		if err != nil {
+			return
+		}
+		// existing targets keep their mode, the rename would replace it
+		mode := os.FileMode(0644)
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		tmp := path + ".i18n-tmp"
+		err = os.WriteFile(tmp, content, mode)
+		if err != nil {
+			return
+		}
+		staged[path] = tmp
+		// os.WriteFile is subject to the umask
+		err = os.Chmod(tmp, mode)
+		if err != nil {
+			return
+		}
+	}
+
+	paths := make([]string, 0, len(staged))
+	for path := range staged {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		err = os.Rename(staged[path], path)
+		if err != nil {
+			return
+		}
+		delete(staged, path)
+	}
+
+	return
+}
@@ -0,0 +1,87 @@
+package wkfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsFile(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.xml")
+	full := filepath.Join(dir, "strings.xml")
+	for path, content := range map[string]string{empty: "", full: "<resources/>"} {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name   string
+		path   string
+		isFile bool
+		exists bool
+	}{
+		{name: "file", path: full, isFile: true, exists: true},
+		{name: "empty file", path: empty, isFile: true, exists: true},
+		{name: "folder", path: dir},
+		{name: "missing", path: filepath.Join(dir, "missing.xml")},
+		{name: "below a file", path: filepath.Join(full, "strings.xml")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFile(tt.path); got != tt.isFile {
+				t.Errorf("IsFile(%v) = %v, want %v", tt.path, got, tt.isFile)
+			}
+			if got := FileExists(tt.path); got != tt.exists {
+				t.Errorf("FileExists(%v) = %v, want %v", tt.path, got, tt.exists)
+			}
+		})
+	}
+
+	if os.Getuid() == 0 {
+		return
+	}
+	locked := filepath.Join(dir, "locked")
+	if err := os.Mkdir(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(locked, 0755) }()
+	if IsFile(filepath.Join(locked, "strings.xml")) || FileExists(filepath.Join(locked, "strings.xml")) {
+		t.Error("a file that cannot be stat exists")
+	}
+}
+
+func TestWriteFilesMode(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "gen.sh")
+	shared := filepath.Join(dir, "strings.xml")
+	created := filepath.Join(dir, "values-fr", "strings.xml")
+	if err := ioutil.WriteFile(script, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(shared, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// ioutil.WriteFile is subject to the umask
+	if err := os.Chmod(shared, 0664); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteFiles(map[string][]byte{script: []byte("new"), shared: []byte("new"), created: []byte("new")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]os.FileMode{script: 0755, shared: 0664, created: 0644} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%v mode = %v, want %v", path, info.Mode().Perm(), want)
+		}
+		if raw, _ := ioutil.ReadFile(path); string(raw) != "new" {
+			t.Errorf("%v = %q, want new", path, raw)
+		}
+	}
+}
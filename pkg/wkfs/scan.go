@@ -24,6 +24,7 @@ type ScanOptions struct {
 	patterns []*regexp.Regexp
 	types    []string
 	mode     scanMode
+	skipDirs map[string]bool
 	// TODO
 	// IgnorePrefix []string
 	// IgnoreType []string
@@ -64,6 +65,18 @@ func WithPatterns(patterns ...string) ScanOpt {
 	}
 }
 
+// WithSkipDirs specifies folder names not to walk into, e.g. build
+func WithSkipDirs(names ...string) ScanOpt {
+	return func(op *ScanOptions) {
+		if op.skipDirs == nil {
+			op.skipDirs = make(map[string]bool)
+		}
+		for _, name := range names {
+			op.skipDirs[name] = true
+		}
+	}
+}
+
 // WithTypes specifies file types to scan for
 func WithTypes(types ...string) ScanOpt {
 	return func(op *ScanOptions) {
@@ -98,6 +111,10 @@ func Scan(dir string, opts ...ScanOpt) (files, folders []string, err error) {
 
 		fm := f.Mode()
 
+		if fm.IsDir() && path != dir && options.skipDirs[f.Name()] {
+			return filepath.SkipDir
+		}
+
 		// scan mode
 		if options.mode == scanModeFilesOnly && !fm.IsRegular() {
 			return nil
@@ -0,0 +1,63 @@
+package wkfs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestScanSkipDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"app/src/main/res/values",
+		"app/src/huawei/res/values-fr",
+		"app/build/intermediates/res/values",
+		"build/values",
+		".gradle/values",
+		"lib/src/main/res/values",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rel := func(paths []string) string {
+		var got []string
+		for _, p := range paths {
+			r, err := filepath.Rel(root, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, filepath.ToSlash(r))
+		}
+		sort.Strings(got)
+		return strings.Join(got, ",")
+	}
+
+	_, folders, err := Scan(root, WithFoldersOnly(), WithPatterns("^values"), WithSkipDirs("build", ".gradle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rel(folders), "app/src/huawei/res/values-fr,app/src/main/res/values,lib/src/main/res/values"; got != want {
+		t.Errorf("Scan() with skipped dirs = %v, want %v", got, want)
+	}
+
+	_, folders, err = Scan(root, WithFoldersOnly(), WithPatterns("^values"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Split(rel(folders), ",")); n != 6 {
+		t.Errorf("Scan() finds %d folders, want all 6", n)
+	}
+
+	// the root itself is walked even if its name is skipped
+	_, folders, err = Scan(filepath.Join(root, "build"), WithFoldersOnly(), WithPatterns("^values"), WithSkipDirs("build"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rel(folders); got != "build/values" {
+		t.Errorf("Scan() of a skipped root = %v, want build/values", got)
+	}
+}